@@ -2,7 +2,6 @@ package gwda
 
 import (
 	"strconv"
-	"strings"
 )
 
 type W3CActions []map[string]interface{}
@@ -15,27 +14,80 @@ func NewW3CActions(capacity ...int) *W3CActions {
 	return &tmp
 }
 
-func (act *W3CActions) SendKeys(text string) *W3CActions {
+type keyEvent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func (act *W3CActions) _newKeyboard(events []keyEvent) *W3CActions {
 	keyboard := make(map[string]interface{})
 	keyboard["type"] = "key"
 	keyboard["id"] = "keyboard" + strconv.FormatInt(int64(len(*act)+1), 10)
+	keyboard["actions"] = events
+	*act = append(*act, keyboard)
+	return act
+}
 
-	ss := strings.Split(text, "")
-	type KeyEvent struct {
-		Type  string `json:"type"`
-		Value string `json:"value"`
+func typingEvents(text string) []keyEvent {
+	graphemes := splitGraphemes(text)
+	events := make([]keyEvent, 0, len(graphemes)*2)
+	for i := range graphemes {
+		events = append(
+			events,
+			keyEvent{Type: "keyDown", Value: graphemes[i]},
+			keyEvent{Type: "keyUp", Value: graphemes[i]},
+		)
 	}
-	actOptKey := make([]KeyEvent, 0, len(ss)+1)
-	for i := range ss {
-		actOptKey = append(
-			actOptKey,
-			KeyEvent{Type: "keyDown", Value: ss[i]},
-			KeyEvent{Type: "keyUp", Value: ss[i]},
+	return events
+}
+
+// SendKeys Types the text, each user-perceived character (such as an emoji with a skin tone) as one key.
+func (act *W3CActions) SendKeys(text string) *W3CActions {
+	return act._newKeyboard(typingEvents(text))
+}
+
+// PressKey Presses and releases each key in order.
+func (act *W3CActions) PressKey(key KeyboardKey, keys ...KeyboardKey) *W3CActions {
+	keys = append([]KeyboardKey{key}, keys...)
+	events := make([]keyEvent, 0, len(keys)*2)
+	for i := range keys {
+		events = append(
+			events,
+			keyEvent{Type: "keyDown", Value: string(keys[i])},
+			keyEvent{Type: "keyUp", Value: string(keys[i])},
 		)
 	}
-	keyboard["actions"] = actOptKey
-	*act = append(*act, keyboard)
-	return act
+	return act._newKeyboard(events)
+}
+
+// KeyChord Holds all keys down together and releases them in reverse order, e.g. `Command` + `A`.
+//  Printable keys can be given as KeyboardKey("a")
+func (act *W3CActions) KeyChord(key KeyboardKey, keys ...KeyboardKey) *W3CActions {
+	keys = append([]KeyboardKey{key}, keys...)
+	events := make([]keyEvent, 0, len(keys)*2)
+	for i := range keys {
+		events = append(events, keyEvent{Type: "keyDown", Value: string(keys[i])})
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		events = append(events, keyEvent{Type: "keyUp", Value: string(keys[i])})
+	}
+	return act._newKeyboard(events)
+}
+
+// SendKeysWithModifiers Types the text while holding the modifiers,
+// for apps that react to hardware keyboard shortcuts.
+func (act *W3CActions) SendKeysWithModifiers(text string, modifier KeyboardKey, modifiers ...KeyboardKey) *W3CActions {
+	modifiers = append([]KeyboardKey{modifier}, modifiers...)
+	typing := typingEvents(text)
+	events := make([]keyEvent, 0, len(modifiers)*2+len(typing))
+	for i := range modifiers {
+		events = append(events, keyEvent{Type: "keyDown", Value: string(modifiers[i])})
+	}
+	events = append(events, typing...)
+	for i := len(modifiers) - 1; i >= 0; i-- {
+		events = append(events, keyEvent{Type: "keyUp", Value: string(modifiers[i])})
+	}
+	return act._newKeyboard(events)
 }
 
 func (act *W3CActions) _newFinger() map[string]interface{} {
@@ -89,8 +141,9 @@ func (fm FingerMove) WithOrigin(element WebElement) FingerMove {
 	return fm
 }
 
+// WithDuration The duration of the move, sent in milliseconds like `FingerAction.Pause`
 func (fm FingerMove) WithDuration(second float64) FingerMove {
-	fm["duration"] = second
+	fm["duration"] = second * 1000
 	return fm
 }
 
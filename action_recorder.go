@@ -0,0 +1,285 @@
+package gwda
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type recordedAction struct {
+	// kind One of `click`, `sendKeys`, `tap`, `swipe`, `pressButton`, `appLaunch`, `appTerminate`, `alertAccept`, `alertDismiss`
+	kind string
+	by   *BySelector
+	text string
+	xy   []float64
+}
+
+// ActionRecorder Records interactions, e.g. made through the Inspector, and generates the Go code of a test replaying them
+type ActionRecorder struct {
+	mu      sync.Mutex
+	actions []recordedAction
+}
+
+func NewActionRecorder() *ActionRecorder {
+	return new(ActionRecorder)
+}
+
+func (r *ActionRecorder) record(action recordedAction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.actions = append(r.actions, action)
+}
+
+// RecordClick Records `WebDriver.FindElement(by)` followed by `WebElement.Click`
+func (r *ActionRecorder) RecordClick(by BySelector) {
+	r.record(recordedAction{kind: "click", by: &by})
+}
+
+// RecordElementSendKeys Records `WebDriver.FindElement(by)` followed by `WebElement.SendKeys`
+func (r *ActionRecorder) RecordElementSendKeys(by BySelector, text string) {
+	r.record(recordedAction{kind: "sendKeys", by: &by, text: text})
+}
+
+// RecordSendKeys Records `WebDriver.SendKeys` into the focused element
+func (r *ActionRecorder) RecordSendKeys(text string) {
+	r.record(recordedAction{kind: "sendKeys", text: text})
+}
+
+// RecordTap Records `WebDriver.TapFloat`
+func (r *ActionRecorder) RecordTap(x, y float64) {
+	r.record(recordedAction{kind: "tap", xy: []float64{x, y}})
+}
+
+// RecordSwipe Records `WebDriver.SwipeFloat`
+func (r *ActionRecorder) RecordSwipe(fromX, fromY, toX, toY float64) {
+	r.record(recordedAction{kind: "swipe", xy: []float64{fromX, fromY, toX, toY}})
+}
+
+// RecordPressButton Records `WebDriver.PressButton`
+func (r *ActionRecorder) RecordPressButton(devBtn DeviceButton) {
+	r.record(recordedAction{kind: "pressButton", text: string(devBtn)})
+}
+
+// RecordAppLaunch Records `WebDriver.AppLaunch`
+func (r *ActionRecorder) RecordAppLaunch(bundleId string) {
+	r.record(recordedAction{kind: "appLaunch", text: bundleId})
+}
+
+// RecordAppTerminate Records `WebDriver.AppTerminate`
+func (r *ActionRecorder) RecordAppTerminate(bundleId string) {
+	r.record(recordedAction{kind: "appTerminate", text: bundleId})
+}
+
+// RecordAlertAccept Records `WebDriver.AlertAccept`, with the label of the button if not empty
+func (r *ActionRecorder) RecordAlertAccept(label string) {
+	r.record(recordedAction{kind: "alertAccept", text: label})
+}
+
+// RecordAlertDismiss Records `WebDriver.AlertDismiss`, with the label of the button if not empty
+func (r *ActionRecorder) RecordAlertDismiss(label string) {
+	r.record(recordedAction{kind: "alertDismiss", text: label})
+}
+
+// RecordTapAt Records a tap at the coordinate of the screen.
+// It is recorded as a click on the element at the coordinate if the element can be located by its attributes,
+// otherwise as a tap at the coordinate.
+func (r *ActionRecorder) RecordTapAt(root *SourceNode, x, y float64) {
+	if node := root.HitTest(x, y); node != nil {
+		if by, ok := recordedSelector(node); ok {
+			r.RecordClick(by)
+			return
+		}
+	}
+	r.RecordTap(x, y)
+}
+
+// Len Returns the number of recorded actions
+func (r *ActionRecorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.actions)
+}
+
+// Reset Discards the recorded actions
+func (r *ActionRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.actions = nil
+}
+
+// HitTest Returns the smallest visible node containing the coordinate, nil if none does
+func (n *SourceNode) HitTest(x, y float64) (found *SourceNode) {
+	n.Walk(func(node *SourceNode) bool {
+		if node == n || !node.IsVisible {
+			return true
+		}
+		rect := node.Rect
+		if x < float64(rect.X) || y < float64(rect.Y) || x >= float64(rect.X+rect.Width) || y >= float64(rect.Y+rect.Height) {
+			return true
+		}
+		if found == nil || rect.Width*rect.Height <= found.Rect.Width*found.Rect.Height {
+			found = node
+		}
+		return true
+	})
+	return
+}
+
+// recordedSelector The most robust selector of the node, see `SourceNode.SuggestSelectors`.
+// Nodes without a name or a label are not located, a tap at the coordinate is as robust as their class chain or XPath.
+func recordedSelector(node *SourceNode) (by BySelector, ok bool) {
+	if node.Name == "" && node.Label == "" {
+		return BySelector{}, false
+	}
+	return node.SuggestSelectors()[0], true
+}
+
+// Steps Generates the statements replaying the actions, which expect `driver`, `err` and `t` to be declared
+func (r *ActionRecorder) Steps() string {
+	steps, _ := r.steps()
+	return steps
+}
+
+// steps Also reports whether the statements look elements up, which need `elem` to be declared
+func (r *ActionRecorder) steps() (steps string, findsElements bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var buf bytes.Buffer
+	check := "; err != nil {\n\tt.Fatal(err)\n}\n"
+	lastClicked := ""
+	for _, action := range r.actions {
+		if action.by != nil {
+			// typing into the element just clicked shares its lookup,
+			// any other action may change the screen and is replayed on the element found afresh
+			if by := goSelector(*action.by); action.kind != "sendKeys" || by != lastClicked {
+				buf.WriteString("if elem, err = driver.FindElement(" + by + ")" + check)
+				findsElements = true
+			}
+		}
+		lastClicked = ""
+		if action.kind == "click" {
+			lastClicked = goSelector(*action.by)
+		}
+		switch action.kind {
+		case "click":
+			buf.WriteString("if err = elem.Click()" + check)
+		case "sendKeys":
+			if action.by != nil {
+				buf.WriteString("if err = elem.SendKeys(" + strconv.Quote(action.text) + ")" + check)
+			} else {
+				buf.WriteString("if err = driver.SendKeys(" + strconv.Quote(action.text) + ")" + check)
+			}
+		case "tap":
+			buf.WriteString(fmt.Sprintf("if err = driver.TapFloat(%s, %s)%s", goFloat(action.xy[0]), goFloat(action.xy[1]), check))
+		case "swipe":
+			buf.WriteString(fmt.Sprintf("if err = driver.SwipeFloat(%s, %s, %s, %s)%s",
+				goFloat(action.xy[0]), goFloat(action.xy[1]), goFloat(action.xy[2]), goFloat(action.xy[3]), check))
+		case "pressButton":
+			buf.WriteString("if err = driver.PressButton(" + goDeviceButton(DeviceButton(action.text)) + ")" + check)
+		case "appLaunch":
+			buf.WriteString("if err = driver.AppLaunch(" + strconv.Quote(action.text) + ")" + check)
+		case "appTerminate":
+			buf.WriteString("if _, err = driver.AppTerminate(" + strconv.Quote(action.text) + ")" + check)
+		case "alertAccept", "alertDismiss":
+			label := ""
+			if action.text != "" {
+				label = strconv.Quote(action.text)
+			}
+			method := "AlertAccept"
+			if action.kind == "alertDismiss" {
+				method = "AlertDismiss"
+			}
+			buf.WriteString("if err = driver." + method + "(" + label + ")" + check)
+		}
+	}
+	return buf.String(), findsElements
+}
+
+// TestFile Generates a test file replaying the actions on the first USB device
+func (r *ActionRecorder) TestFile(pkg, testName string) ([]byte, error) {
+	steps, findsElements := r.steps()
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "package %s\n\nimport (\n\t\"testing\"\n\n\t\"github.com/electricbubble/gwda\"\n)\n\n", pkg)
+	fmt.Fprintf(&buf, "func %s(t *testing.T) {\n", testName)
+	buf.WriteString("driver, err := gwda.NewUSBDriver(nil)\nif err != nil {\n\tt.Fatal(err)\n}\n")
+	buf.WriteString("defer func() { _ = driver.Close() }()\n\n")
+	if findsElements {
+		buf.WriteString("var elem gwda.WebElement\n")
+	}
+	buf.WriteString(steps)
+	buf.WriteString("}\n")
+	return format.Source(buf.Bytes())
+}
+
+// goSelector The Go literal of the selector, e.g. `gwda.BySelector{Name: "login"}`.
+// As in `BySelector.getUsingAndValue`, only the first field set is used.
+func goSelector(by BySelector) string {
+	v, t := reflect.ValueOf(by), reflect.TypeOf(by)
+	for i := 0; i < v.NumField(); i++ {
+		literal := ""
+		switch vi := v.Field(i).Interface().(type) {
+		case ElementType:
+			if vi.String() != "UNKNOWN" {
+				literal = goElementType(vi)
+			}
+		case string:
+			if vi != "" {
+				literal = goString(vi)
+			}
+		case ElementAttribute:
+			for k, attr := range vi {
+				switch attr := attr.(type) {
+				case string:
+					literal = fmt.Sprintf("gwda.ElementAttribute{%q: %s}", k, goString(attr))
+				case ElementType:
+					literal = fmt.Sprintf("gwda.ElementAttribute{%q: %s}", k, goElementType(attr))
+				default:
+					literal = fmt.Sprintf("gwda.ElementAttribute{%q: %v}", k, attr)
+				}
+				break
+			}
+		}
+		if literal != "" {
+			return "gwda.BySelector{" + t.Field(i).Name + ": " + literal + "}"
+		}
+	}
+	return "gwda.BySelector{}"
+}
+
+// goElementType e.g. `gwda.ElementType{Button: true}`
+func goElementType(elemType ElementType) string {
+	return "gwda.ElementType{" + strings.TrimPrefix(elemType.String(), "XCUIElementType") + ": true}"
+}
+
+// goString Prefers a raw string for predicates with double quotes
+func goString(s string) string {
+	if strings.Contains(s, `"`) && !strings.ContainsAny(s, "`\r\n") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+func goFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+
+func goDeviceButton(devBtn DeviceButton) string {
+	switch devBtn {
+	case DeviceButtonHome:
+		return "gwda.DeviceButtonHome"
+	case DeviceButtonVolumeUp:
+		return "gwda.DeviceButtonVolumeUp"
+	case DeviceButtonVolumeDown:
+		return "gwda.DeviceButtonVolumeDown"
+	}
+	return "gwda.DeviceButton(" + strconv.Quote(string(devBtn)) + ")"
+}
@@ -0,0 +1,162 @@
+package gwda
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestActionRecorder(t *testing.T) {
+	root, err := ParseSourceTree(inspectorSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := NewActionRecorder()
+	r.RecordAppLaunch("com.example.app")
+	r.RecordTapAt(root, 30, 30)
+	r.RecordTapAt(root, 30, 30)
+	r.RecordTapAt(root, 80, 30)
+	r.RecordClick(BySelector{Name: "OK"})
+	r.RecordElementSendKeys(BySelector{Name: "OK"}, "hello")
+	r.RecordElementSendKeys(BySelector{Name: "OK"}, "\n")
+	r.RecordTapAt(root, 150, 100)
+	r.RecordSwipe(80, 100, 80, 20.5)
+	r.RecordPressButton(DeviceButtonHome)
+	r.RecordAlertAccept("")
+	r.RecordAlertDismiss("Don't Allow")
+	if r.Len() != 12 {
+		t.Fatalf("expected 12 actions, got %d", r.Len())
+	}
+
+	expected := `if err = driver.AppLaunch("com.example.app"); err != nil {
+	t.Fatal(err)
+}
+if elem, err = driver.FindElement(gwda.BySelector{AccessibilityId: "OK"}); err != nil {
+	t.Fatal(err)
+}
+if err = elem.Click(); err != nil {
+	t.Fatal(err)
+}
+if elem, err = driver.FindElement(gwda.BySelector{AccessibilityId: "OK"}); err != nil {
+	t.Fatal(err)
+}
+if err = elem.Click(); err != nil {
+	t.Fatal(err)
+}
+if elem, err = driver.FindElement(gwda.BySelector{Predicate: ` + "`" + `type == "XCUIElementTypeButton" AND label == "Don't \"go\""` + "`" + `}); err != nil {
+	t.Fatal(err)
+}
+if err = elem.Click(); err != nil {
+	t.Fatal(err)
+}
+if elem, err = driver.FindElement(gwda.BySelector{Name: "OK"}); err != nil {
+	t.Fatal(err)
+}
+if err = elem.Click(); err != nil {
+	t.Fatal(err)
+}
+if err = elem.SendKeys("hello"); err != nil {
+	t.Fatal(err)
+}
+if elem, err = driver.FindElement(gwda.BySelector{Name: "OK"}); err != nil {
+	t.Fatal(err)
+}
+if err = elem.SendKeys("\n"); err != nil {
+	t.Fatal(err)
+}
+if err = driver.TapFloat(150.0, 100.0); err != nil {
+	t.Fatal(err)
+}
+if err = driver.SwipeFloat(80.0, 100.0, 80.0, 20.5); err != nil {
+	t.Fatal(err)
+}
+if err = driver.PressButton(gwda.DeviceButtonHome); err != nil {
+	t.Fatal(err)
+}
+if err = driver.AlertAccept(); err != nil {
+	t.Fatal(err)
+}
+if err = driver.AlertDismiss("Don't Allow"); err != nil {
+	t.Fatal(err)
+}
+`
+	if got := r.Steps(); got != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	code, err := r.TestFile("main_test", "TestRecorded")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = parser.ParseFile(token.NewFileSet(), "recorded_test.go", code, 0); err != nil {
+		t.Fatalf("invalid code: %v\n%s", err, code)
+	}
+	if !strings.Contains(string(code), "var elem gwda.WebElement") || !strings.Contains(string(code), "func TestRecorded(t *testing.T) {") {
+		t.Fatalf("unexpected code:\n%s", code)
+	}
+
+	r.Reset()
+	if code, _ = r.TestFile("main_test", "TestEmpty"); strings.Contains(string(code), "elem") {
+		t.Fatalf("unexpected code:\n%s", code)
+	}
+	// the typed text is not a lookup of an element
+	r.RecordSendKeys("element")
+	if code, _ = r.TestFile("main_test", "TestText"); strings.Contains(string(code), "var elem") {
+		t.Fatalf("unexpected code:\n%s", code)
+	}
+}
+
+func TestSourceNodeHitTest(t *testing.T) {
+	root, err := ParseSourceTree(inspectorSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node := root.HitTest(70, 25); node == nil || node.Label != `Don't "go"` {
+		t.Fatalf("unexpected node: %+v", node)
+	}
+	if node := root.HitTest(5, 5); node == nil || node.Type != "XCUIElementTypeWindow" {
+		t.Fatalf("unexpected node: %+v", node)
+	}
+	if node := root.HitTest(500, 5); node != nil {
+		t.Fatalf("unexpected node: %+v", node)
+	}
+}
+
+func TestInspectorRecording(t *testing.T) {
+	wd := &fakeInspectorDriver{fakeImageDriver{screen: newBlockImage(320, 240, 2)}}
+	in := NewInspector(wd)
+	server := httptest.NewServer(in)
+	defer server.Close()
+
+	// the tapped element is located in the tree fetched before the tap, without a refresh of the web UI
+	resp, err := http.Post(server.URL+"/api/tap", "application/json", strings.NewReader(`{"x":30,"y":30}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp, err = http.Get(server.URL + "/api/code"); err != nil {
+		t.Fatal(err)
+	}
+	code, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(code), `driver.FindElement(gwda.BySelector{AccessibilityId: "OK"})`) {
+		t.Fatalf("unexpected code:\n%s", code)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, server.URL+"/api/code", nil)
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || in.Recorder().Len() != 0 {
+		t.Fatalf("unexpected reset: %s %d", resp.Status, in.Recorder().Len())
+	}
+}
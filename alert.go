@@ -0,0 +1,226 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Alert An alert or an action sheet, see `WaitForAlert`
+type Alert struct {
+	Title   string `json:"title"`
+	Message string `json:"message,omitempty"`
+	// IsActionSheet Whether the alert is an action sheet, i.e. `XCUIElementTypeSheet`, rather than `XCUIElementTypeAlert`
+	IsActionSheet bool              `json:"isActionSheet"`
+	Buttons       []*AlertButton    `json:"buttons"`
+	TextFields    []*AlertTextField `json:"textFields,omitempty"`
+
+	driver WebDriver
+	// container The type of the alert in the elements tree, empty if the alert was not found in the tree
+	container string
+}
+
+// AlertButton A button of the alert, in the order of the elements tree
+type AlertButton struct {
+	Label string `json:"label"`
+
+	alert *Alert
+}
+
+// AlertTextField A text field of the alert, e.g. the user name or the password of a login prompt
+type AlertTextField struct {
+	// Value The text, or the placeholder if empty
+	Value    string `json:"value"`
+	IsSecure bool   `json:"isSecure"`
+
+	alert *Alert
+	index int
+}
+
+// WaitForAlert Waits for an alert and returns it.
+// The title, message, buttons and text fields are read from the elements tree,
+// falling back to `WebDriver.AlertText` and `WebDriver.AlertButtons` for the alerts missing from it, e.g. some system alerts.
+//  timeout: Defaults to `DefaultWaitTimeout`
+func WaitForAlert(driver WebDriver, timeout ...time.Duration) (alert *Alert, err error) {
+	if len(timeout) == 0 || timeout[0] <= 0 {
+		timeout = []time.Duration{DefaultWaitTimeout}
+	}
+	var text string
+	condition := func(wd WebDriver) (bool, error) {
+		var err error
+		if text, err = wd.AlertText(); err != nil {
+			if isNoSuchAlert(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	}
+	if err = driver.WaitWithTimeout(condition, timeout[0]); err != nil {
+		return nil, fmt.Errorf("wait for alert: %w", err)
+	}
+
+	root, err := SourceTree(driver)
+	if err != nil {
+		return nil, err
+	}
+	if alert = newAlert(driver, root); alert != nil {
+		if alert.Title == "" {
+			alert.Title, alert.Message = splitAlertText(text)
+		}
+		return alert, nil
+	}
+
+	alert = &Alert{driver: driver}
+	alert.Title, alert.Message = splitAlertText(text)
+	labels, err := driver.AlertButtons()
+	if err != nil {
+		return nil, err
+	}
+	for _, label := range labels {
+		alert.Buttons = append(alert.Buttons, &AlertButton{Label: label, alert: alert})
+	}
+	return alert, nil
+}
+
+// newAlert Reads the first alert or action sheet of the tree, nil if none
+func newAlert(driver WebDriver, root *SourceNode) *Alert {
+	node := root.Find(func(n *SourceNode) bool {
+		return n.Type == "XCUIElementTypeAlert" || n.Type == "XCUIElementTypeSheet"
+	})
+	if node == nil {
+		return nil
+	}
+	alert := &Alert{driver: driver, container: node.Type, IsActionSheet: node.Type == "XCUIElementTypeSheet"}
+	var texts []string
+	node.Walk(func(n *SourceNode) bool {
+		switch n.Type {
+		case "XCUIElementTypeStaticText":
+			if text := n.Label; text != "" {
+				texts = append(texts, text)
+			}
+		case "XCUIElementTypeButton":
+			alert.Buttons = append(alert.Buttons, &AlertButton{Label: n.Label, alert: alert})
+			// the texts of the buttons are not part of the message
+			return false
+		case "XCUIElementTypeTextField", "XCUIElementTypeSecureTextField":
+			alert.TextFields = append(alert.TextFields, &AlertTextField{
+				Value:    n.Value,
+				IsSecure: n.Type == "XCUIElementTypeSecureTextField",
+				alert:    alert,
+				index:    len(alert.TextFields),
+			})
+			return false
+		}
+		return true
+	})
+	if len(texts) != 0 {
+		alert.Title, alert.Message = texts[0], strings.Join(texts[1:], "\n")
+	}
+	return alert
+}
+
+// splitAlertText Splits the text of `WebDriver.AlertText`, the title being its first line
+func splitAlertText(text string) (title, message string) {
+	if i := strings.Index(text, "\n"); i >= 0 {
+		return text[:i], text[i+1:]
+	}
+	return text, ""
+}
+
+// Button Returns the button of the label, nil if none
+func (a *Alert) Button(label string) *AlertButton {
+	for _, button := range a.Buttons {
+		if button.Label == label {
+			return button
+		}
+	}
+	return nil
+}
+
+// Tap Taps the button of the label
+func (a *Alert) Tap(label string) error {
+	button := a.Button(label)
+	if button == nil {
+		return fmt.Errorf("%w: alert button '%s'", errNoSuchElement, label)
+	}
+	return button.Tap()
+}
+
+// Accept Accepts the alert, see `WebDriver.AlertAccept`
+func (a *Alert) Accept() error {
+	return a.driver.AlertAccept()
+}
+
+// Dismiss Dismisses the alert, see `WebDriver.AlertDismiss`
+func (a *Alert) Dismiss() error {
+	return a.driver.AlertDismiss()
+}
+
+// elements Finds the elements inside the alert
+func (a *Alert) elements(by BySelector) (elements []WebElement, err error) {
+	var container WebElement
+	if container, err = a.driver.FindElement(BySelector{ClassChain: "**/" + a.container}); err != nil {
+		return nil, err
+	}
+	return container.FindElements(by)
+}
+
+// Tap Taps the button, by its label through `WebDriver.AlertAccept` if the alert was not found in the elements tree
+func (b *AlertButton) Tap() error {
+	if b.alert.container == "" {
+		return b.alert.driver.AlertAccept(b.Label)
+	}
+	// found by label, as the buttons of the alert also include those inside its text fields, e.g. `Clear text`
+	nth := 0
+	for _, button := range b.alert.Buttons {
+		if button == b {
+			break
+		}
+		if button.Label == b.Label {
+			nth++
+		}
+	}
+	buttons, err := b.alert.elements(BySelector{Predicate: fmt.Sprintf("type == %s AND label == %s",
+		predicateLiteral("XCUIElementTypeButton"), predicateLiteral(b.Label))})
+	if err != nil {
+		return err
+	}
+	if nth >= len(buttons) {
+		return fmt.Errorf("%w: alert button '%s'", errNoSuchElement, b.Label)
+	}
+	return buttons[nth].Click()
+}
+
+// element Finds the text field among the text fields of the alert
+func (f *AlertTextField) element() (WebElement, error) {
+	if f.alert.container == "" {
+		return nil, errors.New("alert not found in the elements tree")
+	}
+	fields, err := f.alert.elements(BySelector{Predicate: `type IN {"XCUIElementTypeTextField", "XCUIElementTypeSecureTextField"}`})
+	if err != nil {
+		return nil, err
+	}
+	if f.index >= len(fields) {
+		return nil, fmt.Errorf("%w: alert text field %d", errNoSuchElement, f.index+1)
+	}
+	return fields[f.index], nil
+}
+
+// SendKeys Types into the text field, unlike `WebDriver.AlertSendKeys` which types into the first one
+func (f *AlertTextField) SendKeys(text string, frequency ...int) error {
+	elem, err := f.element()
+	if err != nil {
+		return err
+	}
+	return elem.SendKeys(text, frequency...)
+}
+
+func (f *AlertTextField) Clear() error {
+	elem, err := f.element()
+	if err != nil {
+		return err
+	}
+	return elem.Clear()
+}
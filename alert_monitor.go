@@ -0,0 +1,272 @@
+package gwda
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AlertRule Handles the alerts matching all of its conditions.
+// The first matching rule of the monitor handles the alert.
+type AlertRule struct {
+	// Name Identifies the rule in the logs
+	Name string
+	// TextMatches Matches the text of the alert, see `WebDriver.AlertText`
+	TextMatches *regexp.Regexp
+	// ButtonsContain Matches the alerts with a button of the label
+	ButtonsContain string
+
+	// Action Accepts or dismisses the alert.
+	//  Defaults to `AlertActionAccept`
+	Action AlertAction
+	// Tap The label of the button to tap, the default button of the action if empty
+	Tap string
+	// Handle Replaces the action, e.g. to type into the alert first
+	Handle func(driver WebDriver, text string, buttons []string) error
+}
+
+func (rule AlertRule) match(text string, buttons []string) bool {
+	if rule.TextMatches != nil && !rule.TextMatches.MatchString(text) {
+		return false
+	}
+	if rule.ButtonsContain != "" {
+		for _, button := range buttons {
+			if button == rule.ButtonsContain {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (rule AlertRule) handle(driver WebDriver, text string, buttons []string) (action string, err error) {
+	if rule.Handle != nil {
+		return "handle", rule.Handle(driver, text, buttons)
+	}
+	var label []string
+	if rule.Tap != "" {
+		label = []string{rule.Tap}
+	}
+	if rule.Action == AlertActionDismiss {
+		action, err = "dismiss", driver.AlertDismiss(label...)
+	} else {
+		action, err = "accept", driver.AlertAccept(label...)
+	}
+	if rule.Tap != "" {
+		action = "tap " + rule.Tap
+	}
+	return
+}
+
+// HandledAlert An alert handled by the AlertMonitor
+type HandledAlert struct {
+	Time    time.Time
+	Rule    string
+	Text    string
+	Buttons []string
+	// Action e.g. `accept`, `dismiss`, `tap Allow`, or `handle` for `AlertRule.Handle`
+	Action string
+}
+
+// AlertMonitorOption Configure the behavior of AlertMonitor
+type AlertMonitorOption func(m *AlertMonitor)
+
+// WithAlertMonitorRules The rules to handle the alerts, in order
+func WithAlertMonitorRules(rules ...AlertRule) AlertMonitorOption {
+	return func(m *AlertMonitor) {
+		m.rules = append(m.rules, rules...)
+	}
+}
+
+// WithAlertMonitorLogger Logs the handled alerts and the errors of the background poll.
+//  Defaults to `log.Printf`
+func WithAlertMonitorLogger(logf func(format string, args ...interface{})) AlertMonitorOption {
+	return func(m *AlertMonitor) {
+		if logf != nil {
+			m.logf = logf
+		}
+	}
+}
+
+// AlertMonitor Handles the alerts by rules, e.g. the permission prompts popping up during tests.
+// Alerts are checked by `Check`, before every action of the driver after `Attach`, or on a background poll after `Start`.
+// Alerts matching no rule are left for the test.
+type AlertMonitor struct {
+	driver WebDriver
+	logf   func(format string, args ...interface{})
+
+	mu      sync.Mutex
+	rules   []AlertRule
+	paused  bool
+	handled []HandledAlert
+
+	// checking Skips the checks while one is in progress, as the monitor's own alert calls run the driver hook
+	checking int32
+
+	pollMu sync.Mutex
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func NewAlertMonitor(driver WebDriver, opts ...AlertMonitorOption) *AlertMonitor {
+	m := &AlertMonitor{driver: driver, logf: log.Printf}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// AddRules Appends the rules, which are tried after the existing ones
+func (m *AlertMonitor) AddRules(rules ...AlertRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules = append(m.rules, rules...)
+}
+
+// Pause Stops handling alerts, e.g. while a test asserts on an alert
+func (m *AlertMonitor) Pause() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = true
+}
+
+func (m *AlertMonitor) Resume() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.paused = false
+}
+
+func (m *AlertMonitor) IsPaused() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.paused
+}
+
+// Handled Returns the alerts handled so far
+func (m *AlertMonitor) Handled() []HandledAlert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	handled := make([]HandledAlert, len(m.handled))
+	copy(handled, m.handled)
+	return handled
+}
+
+// Check Handles the alert if one is present and matches a rule, reporting whether it was handled
+func (m *AlertMonitor) Check() (handled bool, err error) {
+	if !atomic.CompareAndSwapInt32(&m.checking, 0, 1) {
+		return false, nil
+	}
+	defer atomic.StoreInt32(&m.checking, 0)
+
+	m.mu.Lock()
+	paused, rules := m.paused, m.rules
+	m.mu.Unlock()
+	if paused || len(rules) == 0 {
+		return false, nil
+	}
+
+	text, err := m.driver.AlertText()
+	if err != nil {
+		if isNoSuchAlert(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("alert monitor: %w", err)
+	}
+	buttons, err := m.driver.AlertButtons()
+	if err != nil {
+		return false, fmt.Errorf("alert monitor: %w", err)
+	}
+	for i, rule := range rules {
+		if !rule.match(text, buttons) {
+			continue
+		}
+		name := rule.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+		action, err := rule.handle(m.driver, text, buttons)
+		if err != nil {
+			return false, fmt.Errorf("alert monitor: rule %s: %w", name, err)
+		}
+		m.mu.Lock()
+		m.handled = append(m.handled, HandledAlert{Time: time.Now(), Rule: name, Text: text, Buttons: buttons, Action: action})
+		m.mu.Unlock()
+		m.logf("alert monitor: rule %s: %s on %q %q", name, action, text, buttons)
+		return true, nil
+	}
+	return false, nil
+}
+
+// isNoSuchAlert WDA replies `no such alert` when no alert is present
+func isNoSuchAlert(err error) bool {
+	return strings.HasPrefix(err.Error(), "no such alert")
+}
+
+// Start Checks for alerts in the background, every second by default, until `Stop`
+func (m *AlertMonitor) Start(interval ...time.Duration) {
+	m.pollMu.Lock()
+	defer m.pollMu.Unlock()
+	if m.stop != nil {
+		return
+	}
+	if len(interval) == 0 || interval[0] <= 0 {
+		interval = []time.Duration{time.Second}
+	}
+	m.stop, m.done = make(chan struct{}), make(chan struct{})
+	go m.poll(interval[0], m.stop, m.done)
+}
+
+func (m *AlertMonitor) poll(interval time.Duration, stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if _, err := m.Check(); err != nil {
+				m.logf("%v", err)
+			}
+		}
+	}
+}
+
+// Stop Stops the background poll, waiting for the check in progress
+func (m *AlertMonitor) Stop() {
+	m.pollMu.Lock()
+	defer m.pollMu.Unlock()
+	if m.stop == nil {
+		return
+	}
+	close(m.stop)
+	<-m.done
+	m.stop, m.done = nil, nil
+}
+
+// Attach Checks for alerts before every action of the driver, i.e. every POST request but the alert ones,
+// which costs a request per action. It replaces the monitor attached before, if any.
+func (m *AlertMonitor) Attach() error {
+	wd, ok := m.driver.(*remoteWD)
+	if !ok {
+		return fmt.Errorf("alert monitor: attach: unsupported driver %T", m.driver)
+	}
+	wd.setAlertHook(func() {
+		if _, err := m.Check(); err != nil {
+			m.logf("%v", err)
+		}
+	})
+	return nil
+}
+
+// Detach Stops the checks before the actions of the driver
+func (m *AlertMonitor) Detach() {
+	if wd, ok := m.driver.(*remoteWD); ok {
+		wd.setAlertHook(nil)
+	}
+}
@@ -0,0 +1,185 @@
+package gwda
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeAlertDriver struct {
+	WebDriver
+	mu      sync.Mutex
+	text    string
+	buttons []string
+	calls   []string
+}
+
+func (wd *fakeAlertDriver) AlertText() (string, error) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	if wd.text == "" {
+		return "", errors.New("no such alert: An attempt was made to operate on a modal dialog when one was not open")
+	}
+	return wd.text, nil
+}
+
+func (wd *fakeAlertDriver) AlertButtons() ([]string, error) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	return wd.buttons, nil
+}
+
+func (wd *fakeAlertDriver) AlertAccept(label ...string) error {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.calls = append(wd.calls, fmt.Sprintf("accept %v", label))
+	wd.text = ""
+	return nil
+}
+
+func (wd *fakeAlertDriver) AlertDismiss(label ...string) error {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.calls = append(wd.calls, fmt.Sprintf("dismiss %v", label))
+	wd.text = ""
+	return nil
+}
+
+func (wd *fakeAlertDriver) show(text string, buttons ...string) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	wd.text, wd.buttons = text, buttons
+}
+
+func TestAlertMonitor(t *testing.T) {
+	wd := new(fakeAlertDriver)
+	var logs []string
+	m := NewAlertMonitor(wd,
+		WithAlertMonitorRules(
+			AlertRule{Name: "notifications", TextMatches: regexp.MustCompile(`Allow.*Notifications`), Tap: "Allow"},
+			AlertRule{ButtonsContain: "Not Now", Action: AlertActionDismiss},
+		),
+		WithAlertMonitorLogger(func(format string, args ...interface{}) { logs = append(logs, fmt.Sprintf(format, args...)) }),
+	)
+
+	if handled, err := m.Check(); err != nil || handled {
+		t.Fatalf("expected no alert, got %v %v", handled, err)
+	}
+	wd.show("“Demo” Would Like to Send You Notifications", "Don’t Allow", "Allow")
+	if handled, err := m.Check(); err != nil || handled {
+		t.Fatalf("expected no matching rule, got %v %v", handled, err)
+	}
+	wd.show("Allow Demo to send Notifications?", "Don’t Allow", "Allow")
+	if handled, err := m.Check(); err != nil || !handled {
+		t.Fatalf("expected the alert to be handled, got %v %v", handled, err)
+	}
+	wd.show("Save Password?", "Save Password", "Not Now")
+	m.Pause()
+	if handled, _ := m.Check(); handled || !m.IsPaused() {
+		t.Fatal("expected the paused monitor to leave the alert")
+	}
+	m.Resume()
+	if handled, err := m.Check(); err != nil || !handled {
+		t.Fatalf("expected the alert to be handled, got %v %v", handled, err)
+	}
+
+	if expected := []string{"accept [Allow]", "dismiss []"}; !reflect.DeepEqual(wd.calls, expected) {
+		t.Fatalf("expected %q, got %q", expected, wd.calls)
+	}
+	handled := m.Handled()
+	if len(handled) != 2 || handled[0].Rule != "notifications" || handled[0].Action != "tap Allow" ||
+		handled[1].Rule != "#2" || handled[1].Action != "dismiss" || handled[1].Text != "Save Password?" {
+		t.Fatalf("unexpected handled alerts: %+v", handled)
+	}
+	if len(logs) != 2 || !strings.Contains(logs[0], `rule notifications: tap Allow on "Allow Demo to send Notifications?"`) {
+		t.Fatalf("unexpected logs: %q", logs)
+	}
+}
+
+func TestAlertMonitorStart(t *testing.T) {
+	wd := new(fakeAlertDriver)
+	m := NewAlertMonitor(wd, WithAlertMonitorLogger(func(string, ...interface{}) {}))
+	m.AddRules(AlertRule{})
+	m.Start(time.Millisecond)
+	defer m.Stop()
+
+	wd.show("Anything")
+	for i := 0; len(m.Handled()) == 0; i++ {
+		if i == 1000 {
+			t.Fatal("expected the background poll to handle the alert")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	m.Stop()
+	if handled := m.Handled(); handled[0].Action != "accept" {
+		t.Fatalf("unexpected handled alert: %+v", handled[0])
+	}
+}
+
+func TestAlertMonitorAttach(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	alert := "Allow Notifications?"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		var value interface{}
+		switch r.URL.Path {
+		case "/session/s/alert/text":
+			if alert == "" {
+				w.WriteHeader(http.StatusNotFound)
+				value = map[string]string{"error": "no such alert", "message": "no alert"}
+			} else {
+				value = alert
+			}
+		case "/session/s/wda/alert/buttons":
+			value = []string{"Allow"}
+		case "/alert/accept":
+			alert = ""
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"value": value})
+	}))
+	defer server.Close()
+
+	wd := &remoteWD{sessionId: "s"}
+	wd.urlPrefix, _ = url.Parse(server.URL)
+	m := NewAlertMonitor(wd, WithAlertMonitorRules(AlertRule{}), WithAlertMonitorLogger(func(string, ...interface{}) {}))
+	if err := m.Attach(); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := wd.TapFloat(1, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m.Detach()
+	if err := wd.TapFloat(1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"GET /session/s/alert/text",
+		"GET /session/s/wda/alert/buttons",
+		"POST /alert/accept",
+		"POST /session/s/wda/tap/0",
+		"GET /session/s/alert/text",
+		"POST /session/s/wda/tap/0",
+		"POST /session/s/wda/tap/0",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Fatalf("expected %q, got %q", expected, requests)
+	}
+	if err := NewAlertMonitor(new(fakeAlertDriver)).Attach(); err == nil {
+		t.Fatal("expected an error for an unsupported driver")
+	}
+}
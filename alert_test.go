@@ -0,0 +1,175 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const alertSource = `{"type":"Application","name":"Demo","children":[
+{"type":"Alert","name":"Sign In","children":[
+{"type":"StaticText","label":"Sign In"},
+{"type":"StaticText","label":"Enter your password\nfor alice"},
+{"type":"TextField","value":"User Name","children":[{"type":"Button","label":"Clear text"}]},
+{"type":"SecureTextField","value":"Password"},
+{"type":"Button","label":"Cancel","children":[{"type":"StaticText","label":"Cancel"}]},
+{"type":"Button","label":"OK"}]}]}`
+
+const sheetSource = `{"type":"Application","name":"Demo","children":[
+{"type":"Sheet","children":[
+{"type":"Button","label":"Delete"},
+{"type":"Button","label":"Cancel"}]}]}`
+
+type fakeAlertElement struct {
+	WebElement
+	wd   *fakeTypedAlertDriver
+	name string
+}
+
+func (we fakeAlertElement) FindElements(by BySelector) ([]WebElement, error) {
+	var names []string
+	switch {
+	case strings.HasPrefix(by.Predicate, `type == "XCUIElementTypeButton"`):
+		names = []string{by.Predicate}
+	case by.Predicate != "":
+		names = []string{"field 1", "field 2"}
+	}
+	elements := make([]WebElement, len(names))
+	for i, name := range names {
+		elements[i] = fakeAlertElement{wd: we.wd, name: name}
+	}
+	return elements, nil
+}
+
+func (we fakeAlertElement) Click() error {
+	we.wd.calls = append(we.wd.calls, "click "+we.name)
+	return nil
+}
+
+func (we fakeAlertElement) SendKeys(text string, frequency ...int) error {
+	we.wd.calls = append(we.wd.calls, "type "+we.name+" "+text)
+	return nil
+}
+
+type fakeTypedAlertDriver struct {
+	WebDriver
+	source string
+	text   string
+	// appear The number of polls before the alert appears
+	appear int
+	calls  []string
+}
+
+func (wd *fakeTypedAlertDriver) WaitWithTimeout(condition Condition, timeout time.Duration) error {
+	for start := time.Now(); time.Since(start) <= timeout; {
+		if done, err := condition(wd); err != nil || done {
+			return err
+		}
+	}
+	return errors.New("timeout")
+}
+
+func (wd *fakeTypedAlertDriver) AlertText() (string, error) {
+	if wd.appear > 0 || wd.text == "" {
+		wd.appear--
+		return "", errors.New("no such alert: no alert")
+	}
+	return wd.text, nil
+}
+
+func (wd *fakeTypedAlertDriver) AlertButtons() ([]string, error) {
+	return []string{"Don’t Allow", "Allow"}, nil
+}
+
+func (wd *fakeTypedAlertDriver) AlertAccept(label ...string) error {
+	wd.calls = append(wd.calls, fmt.Sprintf("accept %v", label))
+	return nil
+}
+
+func (wd *fakeTypedAlertDriver) Source(srcOpt ...SourceOption) (string, error) {
+	return wd.source, nil
+}
+
+func (wd *fakeTypedAlertDriver) FindElement(by BySelector) (WebElement, error) {
+	wd.calls = append(wd.calls, "find "+by.ClassChain)
+	return fakeAlertElement{wd: wd, name: "container"}, nil
+}
+
+func TestWaitForAlert(t *testing.T) {
+	wd := &fakeTypedAlertDriver{source: alertSource, text: "Sign In\nEnter your password\nfor alice", appear: 2}
+	alert, err := WaitForAlert(wd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alert.Title != "Sign In" || alert.Message != "Enter your password\nfor alice" || alert.IsActionSheet {
+		t.Fatalf("unexpected alert: %+v", alert)
+	}
+	if len(alert.Buttons) != 2 || alert.Buttons[0].Label != "Cancel" || alert.Button("OK") != alert.Buttons[1] {
+		t.Fatalf("unexpected buttons: %+v", alert.Buttons)
+	}
+	if len(alert.TextFields) != 2 || alert.TextFields[0].IsSecure || !alert.TextFields[1].IsSecure || alert.TextFields[1].Value != "Password" {
+		t.Fatalf("unexpected text fields: %+v", alert.TextFields)
+	}
+
+	if err = alert.TextFields[1].SendKeys("secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err = alert.Tap("OK"); err != nil {
+		t.Fatal(err)
+	}
+	if err = alert.Tap("Later"); !errors.Is(err, errNoSuchElement) {
+		t.Fatalf("expected no such element, got %v", err)
+	}
+	expected := []string{
+		"find **/XCUIElementTypeAlert", "type field 2 secret",
+		"find **/XCUIElementTypeAlert", `click type == "XCUIElementTypeButton" AND label == "OK"`,
+	}
+	if !reflect.DeepEqual(wd.calls, expected) {
+		t.Fatalf("expected %q, got %q", expected, wd.calls)
+	}
+}
+
+func TestWaitForAlertActionSheet(t *testing.T) {
+	wd := &fakeTypedAlertDriver{source: sheetSource, text: "Delete the photo?"}
+	alert, err := WaitForAlert(wd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the title of the sheet is missing from the tree
+	if !alert.IsActionSheet || alert.Title != "Delete the photo?" || alert.Message != "" || len(alert.Buttons) != 2 {
+		t.Fatalf("unexpected alert: %+v", alert)
+	}
+	if err = alert.Buttons[0].Tap(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"find **/XCUIElementTypeSheet", `click type == "XCUIElementTypeButton" AND label == "Delete"`}; !reflect.DeepEqual(wd.calls, expected) {
+		t.Fatalf("expected %q, got %q", expected, wd.calls)
+	}
+}
+
+func TestWaitForAlertFallback(t *testing.T) {
+	wd := &fakeTypedAlertDriver{source: `{"type":"Application"}`, text: "“Demo” Would Like to Send You Notifications\nNotifications may include alerts."}
+	alert, err := WaitForAlert(wd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if alert.Title != "“Demo” Would Like to Send You Notifications" || alert.Message != "Notifications may include alerts." {
+		t.Fatalf("unexpected alert: %+v", alert)
+	}
+	if len(alert.Buttons) != 2 || len(alert.TextFields) != 0 {
+		t.Fatalf("unexpected alert: %+v", alert)
+	}
+	if err = alert.Tap("Allow"); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"accept [Allow]"}; !reflect.DeepEqual(wd.calls, expected) {
+		t.Fatalf("expected %q, got %q", expected, wd.calls)
+	}
+
+	if _, err = WaitForAlert(&fakeTypedAlertDriver{}, time.Millisecond); err == nil {
+		t.Fatal("expected a timeout")
+	}
+}
@@ -0,0 +1,245 @@
+package gwda
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strings"
+)
+
+// Annotation A rect to outline on a screenshot.
+//  Rect: in points, like `WebElement.Rect`
+//  Label: only printable ASCII is drawn, other characters such as CJK are left out
+//  Color: nil picks a color by the index of the annotation
+type Annotation struct {
+	Rect  Rect
+	Label string
+	Color color.Color
+}
+
+var annotationPalette = []color.RGBA{
+	{R: 230, G: 25, B: 75, A: 255},
+	{R: 60, G: 180, B: 75, A: 255},
+	{R: 0, G: 130, B: 200, A: 255},
+	{R: 245, G: 130, B: 48, A: 255},
+	{R: 145, G: 30, B: 180, A: 255},
+	{R: 0, G: 160, B: 160, A: 255},
+	{R: 240, G: 50, B: 230, A: 255},
+	{R: 128, G: 128, B: 0, A: 255},
+}
+
+// AnnotateOption Configure the behavior of AnnotateScreenshot
+type AnnotateOption func(an *annotator)
+
+// WithAnnotateLabels Whether to draw the labels.
+//  Defaults to `true`, but `false` for AnnotateSource
+func WithAnnotateLabels(b bool) AnnotateOption {
+	return func(an *annotator) {
+		an.labels = b
+	}
+}
+
+// WithAnnotateIndices Whether to prefix the labels with the index of the annotation, e.g. `#0`.
+//  Defaults to `true`
+func WithAnnotateIndices(b bool) AnnotateOption {
+	return func(an *annotator) {
+		an.indices = b
+	}
+}
+
+// WithAnnotateLineWidth The width of the outlines in points.
+//  Defaults to `1`
+func WithAnnotateLineWidth(points float64) AnnotateOption {
+	return func(an *annotator) {
+		an.lineWidth = points
+	}
+}
+
+// WithAnnotateFontSize The height of the labels in points.
+//  Defaults to `10`
+func WithAnnotateFontSize(points float64) AnnotateOption {
+	return func(an *annotator) {
+		an.fontSize = points
+	}
+}
+
+type annotator struct {
+	labels    bool
+	indices   bool
+	lineWidth float64
+	fontSize  float64
+}
+
+func newAnnotator(opts ...AnnotateOption) *annotator {
+	an := &annotator{labels: true, indices: true, lineWidth: 1, fontSize: 10}
+	for _, opt := range opts {
+		opt(an)
+	}
+	return an
+}
+
+// AnnotateScreenshot Draws the outlines, labels and indices of the annotations onto a copy of the screenshot.
+// The built-in font covers ASCII only, the characters of other scripts are left out of the labels.
+func AnnotateScreenshot(screenshot DecodedScreenshot, annotations []Annotation, opts ...AnnotateOption) *image.RGBA {
+	return newAnnotator(opts...).draw(screenshot, annotations)
+}
+
+func (an *annotator) draw(screenshot DecodedScreenshot, annotations []Annotation) *image.RGBA {
+	bounds := screenshot.Image.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(img, img.Bounds(), screenshot.Image, bounds.Min, draw.Src)
+
+	ratio, _ := screenshot.ratio()
+	lineWidth := int(math.Max(1, math.Round(an.lineWidth*ratio)))
+	// a glyph is 7 pixels high plus 2 pixels of padding
+	fontScale := int(math.Max(1, math.Round(an.fontSize*ratio/9)))
+
+	for i, annotation := range annotations {
+		c := annotation.Color
+		if c == nil {
+			c = annotationPalette[i%len(annotationPalette)]
+		}
+		rect := screenshot.PointRectToPixel(annotation.Rect).Sub(bounds.Min)
+		if rect.Empty() {
+			continue
+		}
+		uniform := image.NewUniform(c)
+		for _, edge := range []image.Rectangle{
+			image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+lineWidth),
+			image.Rect(rect.Min.X, rect.Max.Y-lineWidth, rect.Max.X, rect.Max.Y),
+			image.Rect(rect.Min.X, rect.Min.Y, rect.Min.X+lineWidth, rect.Max.Y),
+			image.Rect(rect.Max.X-lineWidth, rect.Min.Y, rect.Max.X, rect.Max.Y),
+		} {
+			draw.Draw(img, edge.Intersect(rect), uniform, image.Point{}, draw.Over)
+		}
+
+		if !an.labels {
+			continue
+		}
+		label := asciiLabel(annotation.Label)
+		if an.indices {
+			label = strings.TrimSpace(fmt.Sprintf("#%d %s", i, label))
+		}
+		if label == "" {
+			continue
+		}
+		// above the rect, or inside it at the top of the screen
+		height := 9 * fontScale
+		origin := image.Pt(rect.Min.X, rect.Min.Y-height)
+		if origin.Y < 0 {
+			origin.Y = rect.Min.Y
+		}
+		drawLabel(img, origin, label, fontScale, c)
+	}
+	return img
+}
+
+// asciiLabel Leaves out the characters missing from the built-in font,
+// so that a label in another script keeps at least the type of the element and not a row of placeholders
+func asciiLabel(label string) string {
+	return strings.Join(strings.Fields(strings.Map(func(r rune) rune {
+		if r < ' ' || r > '~' {
+			return ' '
+		}
+		return r
+	}, label)), " ")
+}
+
+// drawLabel Draws white text on a box of the color
+func drawLabel(img *image.RGBA, origin image.Point, text string, scale int, c color.Color) {
+	runes := []rune(text)
+	box := image.Rect(origin.X, origin.Y, origin.X+(len(runes)*6+1)*scale, origin.Y+9*scale)
+	draw.Draw(img, box, image.NewUniform(c), image.Point{}, draw.Src)
+	white := image.NewUniform(color.White)
+	for i, r := range runes {
+		if r < ' ' || r > '~' {
+			r = '?'
+		}
+		glyph := font5x7[r-' ']
+		x0 := origin.X + (1+i*6)*scale
+		for row := 0; row < 7; row++ {
+			for col := 0; col < 5; col++ {
+				if glyph[row]&(0x10>>col) == 0 {
+					continue
+				}
+				px := image.Rect(x0+col*scale, origin.Y+(1+row)*scale, x0+(col+1)*scale, origin.Y+(2+row)*scale)
+				draw.Draw(img, px, white, image.Point{}, draw.Src)
+			}
+		}
+	}
+}
+
+// shortType Strips the `XCUIElementType` prefix
+func shortType(elemType string) string {
+	return strings.TrimPrefix(elemType, "XCUIElementType")
+}
+
+// AnnotateNodes Outlines nodes of the elements tree, labelled with their type and name
+func AnnotateNodes(screenshot DecodedScreenshot, nodes []*SourceNode, opts ...AnnotateOption) *image.RGBA {
+	annotations := make([]Annotation, len(nodes))
+	for i, node := range nodes {
+		label := shortType(node.Type)
+		if node.Name != "" {
+			label += " " + node.Name
+		} else if node.Label != "" {
+			label += " " + node.Label
+		}
+		annotations[i] = Annotation{Rect: node.Rect, Label: label}
+	}
+	return AnnotateScreenshot(screenshot, annotations, opts...)
+}
+
+// AnnotateElements Takes a screenshot and outlines the elements, labelled with their type and text
+func AnnotateElements(driver WebDriver, elements []WebElement, opts ...AnnotateOption) (img *image.RGBA, err error) {
+	annotations := make([]Annotation, len(elements))
+	for i, elem := range elements {
+		if annotations[i].Rect, err = elem.Rect(); err != nil {
+			return nil, err
+		}
+		var elemType, text string
+		if elemType, err = elem.Type(); err != nil {
+			return nil, err
+		}
+		if text, err = elem.Text(); err != nil {
+			return nil, err
+		}
+		annotations[i].Label = strings.TrimSpace(shortType(elemType) + " " + text)
+	}
+	var screenshot DecodedScreenshot
+	if screenshot, err = driver.ScreenshotImage(); err != nil {
+		return nil, err
+	}
+	return AnnotateScreenshot(screenshot, annotations, opts...), nil
+}
+
+// AnnotateSource Takes a screenshot and outlines every visible element of `WebDriver.Source`,
+// colored by depth, to inspect the hierarchy.
+func AnnotateSource(driver WebDriver, opts ...AnnotateOption) (img *image.RGBA, err error) {
+	var root *SourceNode
+	if root, err = SourceTree(driver); err != nil {
+		return nil, err
+	}
+	var screenshot DecodedScreenshot
+	if screenshot, err = driver.ScreenshotImage(); err != nil {
+		return nil, err
+	}
+
+	var annotations []Annotation
+	var walk func(node *SourceNode, depth int)
+	walk = func(node *SourceNode, depth int) {
+		if node.IsVisible && node.Rect.Width > 0 && node.Rect.Height > 0 {
+			annotations = append(annotations, Annotation{
+				Rect:  node.Rect,
+				Label: shortType(node.Type),
+				Color: annotationPalette[depth%len(annotationPalette)],
+			})
+		}
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+	walk(root, 0)
+	return AnnotateScreenshot(screenshot, annotations, append([]AnnotateOption{WithAnnotateLabels(false)}, opts...)...), nil
+}
@@ -0,0 +1,65 @@
+package gwda
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestAnnotateScreenshot(t *testing.T) {
+	screen := image.NewRGBA(image.Rect(0, 0, 200, 400))
+	draw.Draw(screen, screen.Bounds(), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	screenshot := DecodedScreenshot{Image: screen, PixelSize: Size{Width: 200, Height: 400}, PointSize: Size{Width: 100, Height: 200}, Scale: 2}
+
+	red := color.RGBA{R: 255, A: 255}
+	img := AnnotateScreenshot(screenshot, []Annotation{
+		{Rect: Rect{Point: Point{X: 10, Y: 50}, Size: Size{Width: 30, Height: 20}}, Label: "Button OK", Color: red},
+		{Rect: Rect{Size: Size{Width: 100, Height: 20}}},
+	})
+
+	// the outline is scaled to 2 pixels
+	if img.RGBAAt(20, 100) != red || img.RGBAAt(21, 101) != red || img.RGBAAt(22, 102) == red {
+		t.Fatalf("unexpected outline: %v %v %v", img.RGBAAt(20, 100), img.RGBAAt(21, 101), img.RGBAAt(22, 102))
+	}
+	// the label sits above the rect, with white text
+	labelArea := image.Rect(20, 100-18, 20+len("#0 Button OK")*12, 100)
+	white := 0
+	for y := labelArea.Min.Y; y < labelArea.Max.Y; y++ {
+		for x := labelArea.Min.X; x < labelArea.Max.X; x++ {
+			if img.RGBAAt(x, y) == (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+				white++
+			}
+		}
+	}
+	if white == 0 || img.RGBAAt(labelArea.Min.X, labelArea.Min.Y) != red {
+		t.Fatal("expected a label above the rect")
+	}
+	// the label of a rect at the top of the screen is drawn inside it
+	if img.RGBAAt(0, 0) != annotationPalette[1] || img.RGBAAt(5, 5) == (color.RGBA{A: 255}) {
+		t.Fatal("expected a label inside the rect")
+	}
+	// the screenshot is not modified
+	if screen.RGBAAt(20, 100) != (color.RGBA{A: 255}) {
+		t.Fatal("expected a copy of the screenshot")
+	}
+
+	img = AnnotateNodes(screenshot, []*SourceNode{{Type: "XCUIElementTypeButton", Name: "OK", Rect: Rect{Point: Point{X: 50, Y: 100}, Size: Size{Width: 20, Height: 20}}}},
+		WithAnnotateLabels(false))
+	if img.RGBAAt(100, 200) != annotationPalette[0] || img.RGBAAt(100, 190) != (color.RGBA{A: 255}) {
+		t.Fatal("expected an outline without label")
+	}
+}
+
+func Test_asciiLabel(t *testing.T) {
+	for label, expected := range map[string]string{
+		"Button OK":           "Button OK",
+		"Button 确定":           "Button",
+		"StaticText 设置 Wi-Fi": "StaticText Wi-Fi",
+		"取消":                  "",
+	} {
+		if actual := asciiLabel(label); actual != expected {
+			t.Errorf("%q: expected %q, got %q", label, expected, actual)
+		}
+	}
+}
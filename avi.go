@@ -0,0 +1,185 @@
+package gwda
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+// aviWriter Writes JPEG frames into a Motion JPEG AVI (RIFF) file.
+// The header is written with placeholders, which are filled in by close.
+type aviWriter struct {
+	file          *os.File
+	width, height int
+	fps           int
+
+	frames     int
+	maxSize    int
+	moviOffset int64
+	index      bytes.Buffer
+}
+
+const (
+	aviOffsetRIFFSize    = 4
+	aviOffsetAvihFrames  = 48
+	aviOffsetAvihBufSize = 60
+	aviOffsetStrhLength  = 140
+	aviOffsetStrhBufSize = 144
+	aviHeaderSize        = 224
+)
+
+func newAviWriter(name string, width, height, fps int) (aw *aviWriter, err error) {
+	aw = &aviWriter{width: width, height: height, fps: fps}
+	if aw.file, err = os.Create(name); err != nil {
+		return nil, err
+	}
+	if err = aw.writeHeader(); err != nil {
+		_ = aw.file.Close()
+		return nil, err
+	}
+	return aw, nil
+}
+
+func (aw *aviWriter) writeHeader() error {
+	var h bytes.Buffer
+	le := func(vs ...interface{}) {
+		for _, v := range vs {
+			_ = binary.Write(&h, binary.LittleEndian, v)
+		}
+	}
+
+	h.WriteString("RIFF")
+	le(uint32(0)) // patched by close
+	h.WriteString("AVI ")
+
+	h.WriteString("LIST")
+	le(uint32(192))
+	h.WriteString("hdrl")
+
+	h.WriteString("avih")
+	le(uint32(56),
+		uint32(1000000/aw.fps), // microseconds per frame
+		uint32(0),              // max bytes per sec
+		uint32(0),              // padding granularity
+		uint32(0x10),           // AVIF_HASINDEX
+		uint32(0),              // total frames, patched by close
+		uint32(0),              // initial frames
+		uint32(1),              // streams
+		uint32(0),              // suggested buffer size, patched by close
+		uint32(aw.width), uint32(aw.height),
+		[4]uint32{},
+	)
+
+	h.WriteString("LIST")
+	le(uint32(116))
+	h.WriteString("strl")
+
+	h.WriteString("strh")
+	le(uint32(56))
+	h.WriteString("vidsMJPG")
+	le(uint32(0), // flags
+		uint16(0), uint16(0), // priority, language
+		uint32(0),      // initial frames
+		uint32(1),      // scale
+		uint32(aw.fps), // rate, fps = rate / scale
+		uint32(0),      // start
+		uint32(0),      // length, patched by close
+		uint32(0),      // suggested buffer size, patched by close
+		int32(-1),      // quality
+		uint32(0),      // sample size
+		[4]int16{0, 0, int16(aw.width), int16(aw.height)},
+	)
+
+	h.WriteString("strf")
+	le(uint32(40),
+		uint32(40), int32(aw.width), int32(aw.height),
+		uint16(1), uint16(24),
+	)
+	h.WriteString("MJPG")
+	le(uint32(aw.width*aw.height*3), int32(0), int32(0), uint32(0), uint32(0))
+
+	h.WriteString("LIST")
+	le(uint32(0)) // patched by close
+	h.WriteString("movi")
+
+	if h.Len() != aviHeaderSize {
+		return errors.New("avi: invalid header size")
+	}
+	aw.moviOffset = aviHeaderSize - 4
+	_, err := aw.file.Write(h.Bytes())
+	return err
+}
+
+func (aw *aviWriter) writeFrame(data []byte) (err error) {
+	offset, err := aw.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	chunk := make([]byte, 8, 8+len(data)+1)
+	copy(chunk, "00dc")
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(data)))
+	chunk = append(chunk, data...)
+	if len(data)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	if _, err = aw.file.Write(chunk); err != nil {
+		return err
+	}
+
+	aw.index.WriteString("00dc")
+	_ = binary.Write(&aw.index, binary.LittleEndian, [3]uint32{
+		0x10, // AVIIF_KEYFRAME
+		uint32(offset - aw.moviOffset),
+		uint32(len(data)),
+	})
+	aw.frames++
+	if len(data) > aw.maxSize {
+		aw.maxSize = len(data)
+	}
+	return nil
+}
+
+func (aw *aviWriter) close() (err error) {
+	defer func() {
+		if e := aw.file.Close(); err == nil {
+			err = e
+		}
+	}()
+
+	var moviEnd int64
+	if moviEnd, err = aw.file.Seek(0, io.SeekCurrent); err != nil {
+		return err
+	}
+	idx := make([]byte, 8, 8+aw.index.Len())
+	copy(idx, "idx1")
+	binary.LittleEndian.PutUint32(idx[4:], uint32(aw.index.Len()))
+	if _, err = aw.file.Write(append(idx, aw.index.Bytes()...)); err != nil {
+		return err
+	}
+	var fileSize int64
+	if fileSize, err = aw.file.Seek(0, io.SeekCurrent); err != nil {
+		return err
+	}
+
+	patches := []struct {
+		offset int64
+		value  uint32
+	}{
+		{aviOffsetRIFFSize, uint32(fileSize - 8)},
+		{aviOffsetAvihFrames, uint32(aw.frames)},
+		{aviOffsetAvihBufSize, uint32(aw.maxSize)},
+		{aviOffsetStrhLength, uint32(aw.frames)},
+		{aviOffsetStrhBufSize, uint32(aw.maxSize)},
+		{aviHeaderSize - 8, uint32(moviEnd - aw.moviOffset)},
+	}
+	buf := make([]byte, 4)
+	for _, p := range patches {
+		binary.LittleEndian.PutUint32(buf, p.value)
+		if _, err = aw.file.WriteAt(buf, p.offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
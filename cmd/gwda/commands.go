@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/electricbubble/gwda"
+)
+
+type command struct {
+	name    string
+	usage   string
+	summary string
+	// run Returns the result to print, nil prints nothing
+	run func(e *env, args []string) (interface{}, error)
+}
+
+var commands = map[string]*command{}
+
+func register(cmds ...*command) {
+	for _, cmd := range cmds {
+		commands[cmd.name] = cmd
+	}
+}
+
+func init() {
+	register(
+		&command{name: "devices", usage: "", summary: "list the USB devices", run: cmdDevices},
+		&command{name: "status", usage: "", summary: "print the status of WebDriverAgent", run: cmdStatus},
+		&command{name: "info", usage: "", summary: "print the device, screen and active application", run: cmdInfo},
+		&command{name: "screenshot", usage: "[-o FILE | FILE]", summary: "save a screenshot as PNG", run: cmdScreenshot},
+		&command{name: "source", usage: "[-format xml|json|description] [-accessible]", summary: "print the elements tree", run: cmdSource},
+		&command{name: "tap", usage: "X Y", summary: "tap the coordinate in points", run: cmdTap},
+		&command{name: "swipe", usage: "FROM_X FROM_Y TO_X TO_Y", summary: "swipe between the coordinates in points", run: cmdSwipe},
+		&command{name: "type", usage: "[-frequency N] TEXT", summary: "type into the focused element", run: cmdType},
+		&command{name: "launch", usage: "BUNDLE_ID", summary: "launch an application", run: cmdLaunch},
+		&command{name: "terminate", usage: "BUNDLE_ID", summary: "terminate an application", run: cmdTerminate},
+		&command{name: "alert", usage: "text|buttons|accept|dismiss [LABEL] | wait [SECONDS]", summary: "read or handle the current alert", run: cmdAlert},
+		&command{name: "button", usage: "home|volumeUp|volumeDown", summary: "press a hardware button", run: cmdButton},
+		&command{name: "settings", usage: "get [NAME...] | set NAME=VALUE...", summary: "read or change the Appium settings of WebDriverAgent", run: cmdSettings},
+	)
+}
+
+// parseFlags Parses the flags of a command, reporting errUsage on failure
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	fs.SetOutput(ioutil.Discard)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return nil
+}
+
+func parseFloats(args []string, n int) ([]float64, error) {
+	if len(args) != n {
+		return nil, errUsage
+	}
+	values := make([]float64, n)
+	for i, arg := range args {
+		v, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", arg)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func cmdDevices(e *env, args []string) (interface{}, error) {
+	devices, err := gwda.DeviceList()
+	if err != nil {
+		return nil, err
+	}
+	type device struct {
+		SerialNumber   string `json:"serialNumber"`
+		DeviceID       int    `json:"deviceID"`
+		ConnectionType string `json:"connectionType"`
+	}
+	list := make([]device, len(devices))
+	for i, d := range devices {
+		list[i] = device{SerialNumber: d.SerialNumber(), DeviceID: d.DeviceID()}
+		if d.GIDevice() != nil {
+			list[i].ConnectionType = d.GIDevice().Properties().ConnectionType
+		}
+	}
+	if !e.opts.json {
+		lines := make([]string, len(list))
+		for i, d := range list {
+			lines[i] = fmt.Sprintf("%s\t%s", d.SerialNumber, d.ConnectionType)
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+	return list, nil
+}
+
+func cmdStatus(e *env, args []string) (interface{}, error) {
+	driver, err := e.Driver()
+	if err != nil {
+		return nil, err
+	}
+	return driver.Status()
+}
+
+func cmdInfo(e *env, args []string) (interface{}, error) {
+	driver, err := e.Driver()
+	if err != nil {
+		return nil, err
+	}
+	var info struct {
+		Device    gwda.DeviceInfo `json:"device"`
+		Screen    gwda.Screen     `json:"screen"`
+		Window    gwda.Size       `json:"window"`
+		ActiveApp gwda.AppInfo    `json:"activeApp"`
+	}
+	if info.Device, err = driver.DeviceInfo(); err != nil {
+		return nil, err
+	}
+	if info.Screen, err = driver.Screen(); err != nil {
+		return nil, err
+	}
+	if info.Window, err = driver.WindowSize(); err != nil {
+		return nil, err
+	}
+	if info.ActiveApp, err = driver.ActiveAppInfo(); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func cmdScreenshot(e *env, args []string) (interface{}, error) {
+	fs := flag.NewFlagSet("screenshot", flag.ContinueOnError)
+	output := fs.String("o", "", "the file to write, screenshot_<time>.png by default")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	switch {
+	case fs.NArg() == 1 && *output == "":
+		*output = fs.Arg(0)
+	case fs.NArg() != 0:
+		return nil, errUsage
+	}
+	if *output == "" {
+		*output = fmt.Sprintf("screenshot_%s.png", time.Now().Format("20060102_150405"))
+	}
+	driver, err := e.Driver()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := driver.Screenshot()
+	if err != nil {
+		return nil, err
+	}
+	if err = writePNG(*output, raw.Bytes()); err != nil {
+		return nil, err
+	}
+	if e.opts.json {
+		return map[string]string{"path": *output}, nil
+	}
+	return *output, nil
+}
+
+// writePNG Writes the screenshot as PNG, WDA returns JPEG with `screenshotQuality` set
+func writePNG(path string, raw []byte) error {
+	img, format, err := image.Decode(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("decode screenshot: %w", err)
+	}
+	if format == "png" {
+		return ioutil.WriteFile(path, raw, 0644)
+	}
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, img); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func cmdSource(e *env, args []string) (interface{}, error) {
+	fs := flag.NewFlagSet("source", flag.ContinueOnError)
+	format := fs.String("format", "xml", "xml, json or description")
+	accessible := fs.Bool("accessible", false, "print the accessibility tree instead")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 0 {
+		return nil, errUsage
+	}
+	driver, err := e.Driver()
+	if err != nil {
+		return nil, err
+	}
+	if *accessible {
+		return driver.AccessibleSource()
+	}
+	opt := gwda.NewSourceOption()
+	switch *format {
+	case "xml":
+		opt = opt.WithFormatAsXml()
+	case "json":
+		opt = opt.WithFormatAsJson()
+	case "description":
+		opt = opt.WithFormatAsDescription()
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", errUsage, *format)
+	}
+	source, err := driver.Source(opt)
+	if err != nil {
+		return nil, err
+	}
+	if *format == "json" {
+		return json.RawMessage(source), nil
+	}
+	return source, nil
+}
+
+func cmdTap(e *env, args []string) (interface{}, error) {
+	xy, err := parseFloats(args, 2)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := e.Driver()
+	if err != nil {
+		return nil, err
+	}
+	return nil, e.record(driver.TapFloat(xy[0], xy[1]), func(r *gwda.ActionRecorder) { r.RecordTap(xy[0], xy[1]) })
+}
+
+func cmdSwipe(e *env, args []string) (interface{}, error) {
+	xy, err := parseFloats(args, 4)
+	if err != nil {
+		return nil, err
+	}
+	driver, err := e.Driver()
+	if err != nil {
+		return nil, err
+	}
+	return nil, e.record(driver.SwipeFloat(xy[0], xy[1], xy[2], xy[3]), func(r *gwda.ActionRecorder) {
+		r.RecordSwipe(xy[0], xy[1], xy[2], xy[3])
+	})
+}
+
+func cmdType(e *env, args []string) (interface{}, error) {
+	fs := flag.NewFlagSet("type", flag.ContinueOnError)
+	frequency := fs.Int("frequency", 0, "letters per second, 60 by default")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() == 0 {
+		return nil, errUsage
+	}
+	driver, err := e.Driver()
+	if err != nil {
+		return nil, err
+	}
+	text := strings.Join(fs.Args(), " ")
+	if *frequency > 0 {
+		err = driver.SendKeys(text, *frequency)
+	} else {
+		err = driver.SendKeys(text)
+	}
+	return nil, e.record(err, func(r *gwda.ActionRecorder) { r.RecordSendKeys(text) })
+}
+
+func cmdLaunch(e *env, args []string) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, errUsage
+	}
+	driver, err := e.Driver()
+	if err != nil {
+		return nil, err
+	}
+	return nil, e.record(driver.AppLaunch(args[0]), func(r *gwda.ActionRecorder) { r.RecordAppLaunch(args[0]) })
+}
+
+func cmdTerminate(e *env, args []string) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, errUsage
+	}
+	driver, err := e.Driver()
+	if err != nil {
+		return nil, err
+	}
+	terminated, err := driver.AppTerminate(args[0])
+	if err = e.record(err, func(r *gwda.ActionRecorder) { r.RecordAppTerminate(args[0]) }); err != nil {
+		return nil, err
+	}
+	if !e.opts.json {
+		if !terminated {
+			return args[0] + " was not running", nil
+		}
+		return nil, nil
+	}
+	return map[string]bool{"terminated": terminated}, nil
+}
+
+func cmdAlert(e *env, args []string) (interface{}, error) {
+	if len(args) == 0 || len(args) > 2 {
+		return nil, errUsage
+	}
+	var label []string
+	if len(args) == 2 {
+		label = args[1:]
+	}
+	driver, err := e.Driver()
+	if err != nil {
+		return nil, err
+	}
+	switch args[0] {
+	case "text":
+		if len(args) != 1 {
+			return nil, errUsage
+		}
+		return driver.AlertText()
+	case "buttons":
+		if len(args) != 1 {
+			return nil, errUsage
+		}
+		buttons, err := driver.AlertButtons()
+		if err != nil {
+			return nil, err
+		}
+		if !e.opts.json {
+			return strings.Join(buttons, "\n"), nil
+		}
+		return buttons, nil
+	case "wait":
+		var timeout time.Duration
+		if len(args) == 2 {
+			seconds, err := parseFloats(args[1:], 1)
+			if err != nil {
+				return nil, err
+			}
+			timeout = time.Duration(seconds[0] * float64(time.Second))
+		}
+		return gwda.WaitForAlert(driver, timeout)
+	case "accept":
+		return nil, e.record(driver.AlertAccept(label...), func(r *gwda.ActionRecorder) { r.RecordAlertAccept(strings.Join(label, "")) })
+	case "dismiss":
+		return nil, e.record(driver.AlertDismiss(label...), func(r *gwda.ActionRecorder) { r.RecordAlertDismiss(strings.Join(label, "")) })
+	default:
+		return nil, errUsage
+	}
+}
+
+func cmdButton(e *env, args []string) (interface{}, error) {
+	if len(args) != 1 {
+		return nil, errUsage
+	}
+	var button gwda.DeviceButton
+	switch gwda.DeviceButton(args[0]) {
+	case gwda.DeviceButtonHome, gwda.DeviceButtonVolumeUp, gwda.DeviceButtonVolumeDown:
+		button = gwda.DeviceButton(args[0])
+	default:
+		return nil, errUsage
+	}
+	driver, err := e.Driver()
+	if err != nil {
+		return nil, err
+	}
+	return nil, e.record(driver.PressButton(button), func(r *gwda.ActionRecorder) { r.RecordPressButton(button) })
+}
+
+func cmdSettings(e *env, args []string) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, errUsage
+	}
+	switch args[0] {
+	case "get":
+		driver, err := e.Driver()
+		if err != nil {
+			return nil, err
+		}
+		settings, err := driver.GetAppiumSettings()
+		if err != nil {
+			return nil, err
+		}
+		if len(args) == 1 {
+			return settings, nil
+		}
+		selected := make(map[string]interface{}, len(args)-1)
+		for _, name := range args[1:] {
+			v, ok := settings[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown setting %q", name)
+			}
+			selected[name] = v
+		}
+		return selected, nil
+	case "set":
+		if len(args) == 1 {
+			return nil, errUsage
+		}
+		settings := make(map[string]interface{}, len(args)-1)
+		for _, arg := range args[1:] {
+			i := strings.Index(arg, "=")
+			if i <= 0 {
+				return nil, fmt.Errorf("%w: expected NAME=VALUE, got %q", errUsage, arg)
+			}
+			settings[arg[:i]] = parseSettingValue(arg[i+1:])
+		}
+		driver, err := e.Driver()
+		if err != nil {
+			return nil, err
+		}
+		updated, err := driver.SetAppiumSettings(settings)
+		if err != nil {
+			return nil, err
+		}
+		// only the changed settings, the response contains all of them
+		changed := make(map[string]interface{}, len(settings))
+		for name := range settings {
+			changed[name] = updated[name]
+		}
+		return changed, nil
+	default:
+		return nil, errUsage
+	}
+}
+
+// parseSettingValue Reads numbers, booleans, objects and quoted strings as JSON, anything else as a string
+func parseSettingValue(s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	return v
+}
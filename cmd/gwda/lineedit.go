@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// errInterrupted Reported for Ctrl-C, the shell discards the line and continues
+var errInterrupted = errors.New("interrupted")
+
+// completer Returns the candidates for the word before the cursor, and the start of that word
+type completer func(line string) (start int, candidates []string)
+
+// lineEditor A minimal line editor for terminals in raw mode:
+// cursor movement, history and tab completion.
+type lineEditor struct {
+	r        *bufio.Reader
+	w        io.Writer
+	history  []string
+	complete completer
+}
+
+func newLineEditor(r io.Reader, w io.Writer, complete completer) *lineEditor {
+	return &lineEditor{r: bufio.NewReader(r), w: w, complete: complete}
+}
+
+func (le *lineEditor) addHistory(line string) {
+	if line == "" || (len(le.history) != 0 && le.history[len(le.history)-1] == line) {
+		return
+	}
+	le.history = append(le.history, line)
+}
+
+// readLine Reads a line, returning io.EOF for Ctrl-D on an empty line
+func (le *lineEditor) readLine(prompt string) (string, error) {
+	var line []rune
+	pos := 0
+	// the position in the history, len(history) is the line being edited
+	historyPos := len(le.history)
+	var edited []rune
+	lastTab := false
+
+	refresh := func() {
+		_, _ = fmt.Fprintf(le.w, "\r%s%s\x1b[K", prompt, string(line))
+		if back := len(line) - pos; back > 0 {
+			_, _ = fmt.Fprintf(le.w, "\x1b[%dD", back)
+		}
+	}
+	setLine := func(s []rune) {
+		line = append([]rune(nil), s...)
+		pos = len(line)
+		refresh()
+	}
+
+	_, _ = io.WriteString(le.w, prompt)
+	for {
+		r, _, err := le.r.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		isTab := r == '\t'
+		switch r {
+		case '\r', '\n':
+			_, _ = io.WriteString(le.w, "\r\n")
+			return string(line), nil
+		case 3: // Ctrl-C
+			_, _ = io.WriteString(le.w, "^C\r\n")
+			return "", errInterrupted
+		case 4: // Ctrl-D
+			if len(line) == 0 {
+				_, _ = io.WriteString(le.w, "\r\n")
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = append(line[:pos], line[pos+1:]...)
+				refresh()
+			}
+		case 1: // Ctrl-A
+			pos = 0
+			refresh()
+		case 5: // Ctrl-E
+			pos = len(line)
+			refresh()
+		case 21: // Ctrl-U
+			line = append([]rune(nil), line[pos:]...)
+			pos = 0
+			refresh()
+		case 127, 8: // Backspace
+			if pos > 0 {
+				line = append(line[:pos-1], line[pos:]...)
+				pos--
+				refresh()
+			}
+		case '\t':
+			if le.complete == nil {
+				break
+			}
+			start, candidates := le.complete(string(line[:pos]))
+			// the start is a byte offset into the line before the cursor
+			startRunes := utf8.RuneCountInString(string(line[:pos])[:start])
+			word := string(line[startRunes:pos])
+			switch {
+			case len(candidates) == 1:
+				completion := []rune(candidates[0])
+				if !strings.HasSuffix(candidates[0], ".") {
+					completion = append(completion, ' ')
+				}
+				line = append(append(append([]rune(nil), line[:startRunes]...), completion...), line[pos:]...)
+				pos = startRunes + len(completion)
+				refresh()
+			case len(candidates) > 1:
+				if prefix := commonPrefix(candidates); len(prefix) > len(word) {
+					completion := []rune(prefix)
+					line = append(append(append([]rune(nil), line[:startRunes]...), completion...), line[pos:]...)
+					pos = startRunes + len(completion)
+					refresh()
+				} else if lastTab {
+					_, _ = fmt.Fprintf(le.w, "\r\n%s\r\n", strings.Join(candidates, "  "))
+					refresh()
+				}
+			}
+		case 27: // Escape sequences of the arrow keys
+			var seq [2]rune
+			if seq[0], _, err = le.r.ReadRune(); err != nil {
+				return "", err
+			}
+			if seq[0] != '[' && seq[0] != 'O' {
+				break
+			}
+			if seq[1], _, err = le.r.ReadRune(); err != nil {
+				return "", err
+			}
+			switch seq[1] {
+			case 'A': // Up
+				if historyPos > 0 {
+					if historyPos == len(le.history) {
+						edited = append([]rune(nil), line...)
+					}
+					historyPos--
+					setLine([]rune(le.history[historyPos]))
+				}
+			case 'B': // Down
+				if historyPos < len(le.history) {
+					historyPos++
+					if historyPos == len(le.history) {
+						setLine(edited)
+					} else {
+						setLine([]rune(le.history[historyPos]))
+					}
+				}
+			case 'C': // Right
+				if pos < len(line) {
+					pos++
+					refresh()
+				}
+			case 'D': // Left
+				if pos > 0 {
+					pos--
+					refresh()
+				}
+			case 'H':
+				pos = 0
+				refresh()
+			case 'F':
+				pos = len(line)
+				refresh()
+			case '3': // Delete, `ESC [ 3 ~`
+				if r, _, err = le.r.ReadRune(); err != nil {
+					return "", err
+				}
+				if r == '~' && pos < len(line) {
+					line = append(line[:pos], line[pos+1:]...)
+					refresh()
+				}
+			}
+		default:
+			if r < ' ' {
+				break
+			}
+			line = append(line[:pos], append([]rune{r}, line[pos:]...)...)
+			pos++
+			refresh()
+		}
+		lastTab = isTab
+	}
+}
+
+func commonPrefix(ss []string) string {
+	prefix := ss[0]
+	for _, s := range ss[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
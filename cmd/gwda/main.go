@@ -0,0 +1,195 @@
+// Command gwda drives WebDriverAgent from the command line.
+//
+//  gwda [--serial SERIAL | --url URL] [--port PORT] [--json] <command> [args...]
+//
+// Run `gwda help` for the list of commands.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/electricbubble/gwda"
+)
+
+// errUsage Reported for invalid arguments, the usage of the command is printed instead of the error
+var errUsage = errors.New("usage")
+
+type options struct {
+	serial    string
+	url       string
+	port      int
+	mjpegPort int
+	json      bool
+	debug     bool
+}
+
+// env The state shared by the commands
+type env struct {
+	opts   options
+	stdin  io.Reader
+	stdout io.Writer
+
+	driver gwda.WebDriver
+	// connect Creates the driver, replaced in tests
+	connect func(opts options) (gwda.WebDriver, error)
+	// recorder Records the actions while `record start` is on in the shell
+	recorder *gwda.ActionRecorder
+}
+
+// Driver Connects on first use, so that commands like `devices` work without WDA
+func (e *env) Driver() (gwda.WebDriver, error) {
+	if e.driver != nil {
+		return e.driver, nil
+	}
+	driver, err := e.connect(e.opts)
+	if err != nil {
+		return nil, fmt.Errorf("connect: %w", err)
+	}
+	e.driver = driver
+	return driver, nil
+}
+
+func (e *env) Close() {
+	if e.driver != nil {
+		_ = e.driver.Close()
+		e.driver = nil
+	}
+}
+
+// record Records the action if it succeeded while recording
+func (e *env) record(err error, fn func(r *gwda.ActionRecorder)) error {
+	if err == nil && e.recorder != nil {
+		fn(e.recorder)
+	}
+	return err
+}
+
+func connect(opts options) (gwda.WebDriver, error) {
+	if opts.url != "" {
+		if opts.mjpegPort != 0 {
+			return gwda.NewDriver(nil, opts.url, opts.mjpegPort)
+		}
+		return gwda.NewDriver(nil, opts.url)
+	}
+	deviceOpts := []gwda.DeviceOption{gwda.WithSerialNumber(opts.serial)}
+	if opts.port != 0 {
+		deviceOpts = append(deviceOpts, gwda.WithPort(opts.port))
+	}
+	if opts.mjpegPort != 0 {
+		deviceOpts = append(deviceOpts, gwda.WithMjpegPort(opts.mjpegPort))
+	}
+	device, err := gwda.NewDevice(deviceOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return gwda.NewUSBDriver(nil, *device)
+}
+
+// print Writes the result of a command.
+// Strings are written as is, other values as indented JSON, or as a single line of JSON with `--json`.
+func (e *env) print(result interface{}) error {
+	if result == nil {
+		return nil
+	}
+	if s, ok := result.(string); ok && !e.opts.json {
+		_, err := fmt.Fprintln(e.stdout, strings.TrimRight(s, "\n"))
+		return err
+	}
+	enc := json.NewEncoder(e.stdout)
+	if !e.opts.json {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(result)
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	e := &env{stdin: os.Stdin, stdout: stdout, connect: connect}
+
+	fs := flag.NewFlagSet("gwda", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&e.opts.serial, "serial", "", "serial number of the USB device, the first device by default")
+	fs.StringVar(&e.opts.url, "url", "", "URL of WebDriverAgent, e.g. http://localhost:8100, instead of USB")
+	fs.IntVar(&e.opts.port, "port", 0, "port of WebDriverAgent on the USB device (default 8100)")
+	fs.IntVar(&e.opts.mjpegPort, "mjpeg-port", 0, "port of the MJPEG server (default 9100)")
+	fs.BoolVar(&e.opts.json, "json", false, "print results as a single line of JSON")
+	fs.BoolVar(&e.opts.debug, "debug", false, "log the requests to WebDriverAgent")
+	fs.Usage = func() { printUsage(fs) }
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	gwda.SetDebug(e.opts.debug)
+	defer e.Close()
+
+	name, cmdArgs := fs.Arg(0), fs.Args()[1:]
+	if name == "help" {
+		if len(cmdArgs) != 0 {
+			if cmd, ok := commands[cmdArgs[0]]; ok {
+				_, _ = fmt.Fprintf(fs.Output(), "usage: gwda %s %s\n  %s\n", cmd.name, cmd.usage, cmd.summary)
+				return 0
+			}
+		}
+		fs.SetOutput(stdout)
+		fs.Usage()
+		return 0
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		_, _ = fmt.Fprintf(stderr, "gwda: unknown command %q, see `gwda help`\n", name)
+		return 2
+	}
+	if err := execute(e, cmd, cmdArgs); err != nil {
+		if errors.Is(err, errUsage) {
+			_, _ = fmt.Fprintf(stderr, "usage: gwda %s %s\n", cmd.name, cmd.usage)
+			return 2
+		}
+		_, _ = fmt.Fprintf(stderr, "gwda %s: %v\n", name, err)
+		return 1
+	}
+	return 0
+}
+
+func execute(e *env, cmd *command, args []string) error {
+	result, err := cmd.run(e, args)
+	if err != nil {
+		return err
+	}
+	return e.print(result)
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func printUsage(fs *flag.FlagSet) {
+	w := fs.Output()
+	_, _ = fmt.Fprintln(w, "usage: gwda [flags] <command> [args...]")
+	_, _ = fmt.Fprintln(w, "\ncommands:")
+	for _, name := range commandNames() {
+		_, _ = fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+	_, _ = fmt.Fprintln(w, "\nflags:")
+	fs.PrintDefaults()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/electricbubble/gwda"
+)
+
+type fakeDriver struct {
+	gwda.WebDriver
+	calls    []string
+	settings map[string]interface{}
+}
+
+func (wd *fakeDriver) TapFloat(x, y float64) error {
+	wd.calls = append(wd.calls, "tap")
+	return nil
+}
+
+func (wd *fakeDriver) AlertButtons() ([]string, error) {
+	return []string{"Allow", "Don't Allow"}, nil
+}
+
+func (wd *fakeDriver) AlertAccept(label ...string) error {
+	wd.calls = append(wd.calls, "alert accept")
+	return nil
+}
+
+func (wd *fakeDriver) SetAppiumSettings(settings map[string]interface{}) (map[string]interface{}, error) {
+	for k, v := range settings {
+		wd.settings[k] = v
+	}
+	return wd.settings, nil
+}
+
+func (wd *fakeDriver) Screenshot() (*bytes.Buffer, error) {
+	// as with `screenshotQuality` set
+	buf := new(bytes.Buffer)
+	err := jpeg.Encode(buf, image.NewRGBA(image.Rect(0, 0, 4, 4)), nil)
+	return buf, err
+}
+
+func (wd *fakeDriver) Close() error {
+	return nil
+}
+
+func newTestEnv(wd *fakeDriver, json bool) (*env, *bytes.Buffer) {
+	stdout := new(bytes.Buffer)
+	e := &env{stdout: stdout, opts: options{json: json}, connect: func(opts options) (gwda.WebDriver, error) {
+		return wd, nil
+	}}
+	return e, stdout
+}
+
+func TestCommands(t *testing.T) {
+	wd := &fakeDriver{settings: map[string]interface{}{"mjpegServerFramerate": 10.0}}
+
+	e, stdout := newTestEnv(wd, false)
+	if err := execute(e, commands["tap"], []string{"10", "20.5"}); err != nil || len(wd.calls) != 1 || stdout.Len() != 0 {
+		t.Fatalf("tap: %v %v %q", err, wd.calls, stdout.String())
+	}
+	if err := execute(e, commands["tap"], []string{"10"}); !errors.Is(err, errUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+	if err := execute(e, commands["alert"], []string{"buttons"}); err != nil || stdout.String() != "Allow\nDon't Allow\n" {
+		t.Fatalf("alert buttons: %v %q", err, stdout.String())
+	}
+
+	e, stdout = newTestEnv(wd, true)
+	if err := execute(e, commands["alert"], []string{"buttons"}); err != nil || stdout.String() != `["Allow","Don't Allow"]`+"\n" {
+		t.Fatalf("alert buttons: %v %q", err, stdout.String())
+	}
+	stdout.Reset()
+	err := execute(e, commands["settings"], []string{"set", "mjpegServerFramerate=30", "defaultActiveApplication=com.apple.Preferences"})
+	if err != nil || strings.TrimSpace(stdout.String()) != `{"defaultActiveApplication":"com.apple.Preferences","mjpegServerFramerate":30}` {
+		t.Fatalf("settings set: %v %q", err, stdout.String())
+	}
+	if err = execute(e, commands["settings"], []string{"set", "=1"}); !errors.Is(err, errUsage) {
+		t.Fatalf("expected usage error, got %v", err)
+	}
+
+	name := filepath.Join(t.TempDir(), "screen.png")
+	if err = execute(e, commands["screenshot"], []string{name}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(name); !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Fatalf("expected a PNG file, got %q", data)
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if code := run([]string{"nope"}, stdout, stderr); code != 2 || !strings.Contains(stderr.String(), "unknown command") {
+		t.Fatalf("unexpected exit %d: %q", code, stderr.String())
+	}
+	if code := run([]string{"help"}, stdout, stderr); code != 0 || !strings.Contains(stdout.String(), "screenshot") {
+		t.Fatalf("unexpected help %d: %q", code, stdout.String())
+	}
+}
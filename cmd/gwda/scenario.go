@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/electricbubble/gwda/scenario"
+)
+
+func init() {
+	register(&command{
+		name:    "run",
+		usage:   "[-junit FILE] [-report FILE] [-out DIR] [-var NAME=VALUE]... SCENARIO...",
+		summary: "run YAML or JSON scenarios",
+		run:     cmdRun,
+	})
+}
+
+// varsFlag Collects repeated `-var NAME=VALUE` flags
+type varsFlag map[string]string
+
+func (v varsFlag) String() string {
+	return ""
+}
+
+func (v varsFlag) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("expected NAME=VALUE, got %q", s)
+	}
+	v[s[:i]] = s[i+1:]
+	return nil
+}
+
+func cmdRun(e *env, args []string) (interface{}, error) {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	junit := fs.String("junit", "", "write a JUnit XML report")
+	report := fs.String("report", "", "write a JSON report")
+	out := fs.String("out", ".", "the directory of the screenshots")
+	vars := make(varsFlag)
+	fs.Var(vars, "var", "set a variable, may be repeated")
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() == 0 {
+		return nil, errUsage
+	}
+
+	scenarios := make([]*scenario.Scenario, fs.NArg())
+	for i, path := range fs.Args() {
+		s, err := scenario.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		scenarios[i] = s
+	}
+	driver, err := e.Driver()
+	if err != nil {
+		return nil, err
+	}
+
+	logf := func(format string, args ...interface{}) {
+		_, _ = fmt.Fprintf(e.stdout, format+"\n", args...)
+	}
+	var results []*scenario.Result
+	failed := 0
+	for _, s := range scenarios {
+		logf("=== %s", s.Name)
+		result := scenario.Run(driver, s, scenario.WithVars(vars), scenario.WithOutputDir(*out), scenario.WithLogger(logf))
+		if result.Passed {
+			logf("--- PASS %s", s.Name)
+		} else {
+			failed++
+			logf("--- FAIL %s: %s", s.Name, result.Error)
+		}
+		results = append(results, result)
+	}
+
+	for _, r := range []struct {
+		path  string
+		write func(w io.Writer, results ...*scenario.Result) error
+	}{{*junit, scenario.WriteJUnit}, {*report, scenario.WriteJSON}} {
+		if r.path == "" {
+			continue
+		}
+		if err = writeReport(r.path, results, r.write); err != nil {
+			return nil, err
+		}
+	}
+	if failed != 0 {
+		return nil, fmt.Errorf("%d of %d scenarios failed", failed, len(results))
+	}
+	return nil, nil
+}
+
+func writeReport(path string, results []*scenario.Result, write func(w io.Writer, results ...*scenario.Result) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = write(f, results...); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,741 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/electricbubble/gwda"
+)
+
+func init() {
+	register(&command{name: "shell", usage: "", summary: "start an interactive shell that keeps the session open", run: cmdShell})
+}
+
+const shellHelp = `commands:
+  find STRATEGY VALUE      find an element and store it as $N
+  findall STRATEGY VALUE   find all elements and store them as $N, $N+1, ...
+  $N                       describe the element
+  $N.METHOD [ARGS...]      call a method of the element, e.g. $1.click
+  vars                     list the stored elements
+  history                  list the previous commands
+  time on|off              print the duration of each command
+  record start|stop|clear  record the actions as Go code
+  record code [FILE]       print or write the Go test replaying the recorded actions
+  help [COMMAND]           print this help, or the usage of a command
+  exit                     quit the shell
+  any command of the command line, e.g. tap 100 200, screenshot out.png
+
+strategies: %s
+methods:    %s
+pipes:      COMMAND | grep [-v] [-i] PATTERN | head [N] | tail [N] | wc
+`
+
+// shellStrategies The strategies of `find`, see gwda.BySelector
+var shellStrategies = map[string]func(value string) gwda.BySelector{
+	"predicate":       func(v string) gwda.BySelector { return gwda.BySelector{Predicate: v} },
+	"classchain":      func(v string) gwda.BySelector { return gwda.BySelector{ClassChain: v} },
+	"xpath":           func(v string) gwda.BySelector { return gwda.BySelector{XPath: v} },
+	"name":            func(v string) gwda.BySelector { return gwda.BySelector{Name: v} },
+	"id":              func(v string) gwda.BySelector { return gwda.BySelector{Id: v} },
+	"accessibilityid": func(v string) gwda.BySelector { return gwda.BySelector{AccessibilityId: v} },
+	"label": func(v string) gwda.BySelector {
+		return gwda.BySelector{LinkText: gwda.NewElementAttribute().WithLabel(v)}
+	},
+	"partiallabel": func(v string) gwda.BySelector {
+		return gwda.BySelector{PartialLinkText: gwda.NewElementAttribute().WithLabel(v)}
+	},
+	"value": func(v string) gwda.BySelector {
+		return gwda.BySelector{LinkText: gwda.NewElementAttribute().WithValue(v)}
+	},
+	"partialvalue": func(v string) gwda.BySelector {
+		return gwda.BySelector{PartialLinkText: gwda.NewElementAttribute().WithValue(v)}
+	},
+}
+
+func parseSelector(args []string) (by gwda.BySelector, err error) {
+	if len(args) < 2 {
+		return by, errUsage
+	}
+	strategy, ok := shellStrategies[strings.ToLower(args[0])]
+	if !ok {
+		return by, fmt.Errorf("unknown strategy %q, expected one of %s", args[0], strings.Join(strategyNames(), ", "))
+	}
+	return strategy(strings.Join(args[1:], " ")), nil
+}
+
+// selectorString The strategy of `find` and the value of the suggested selector, e.g. `predicate label == 'OK'`
+func selectorString(by gwda.BySelector) string {
+	switch {
+	case by.AccessibilityId != "":
+		return "accessibilityid " + by.AccessibilityId
+	case by.Predicate != "":
+		return "predicate " + by.Predicate
+	case by.ClassChain != "":
+		return "classchain " + by.ClassChain
+	case by.XPath != "":
+		return "xpath " + by.XPath
+	}
+	return ""
+}
+
+type elementMethod struct {
+	usage string
+	run   func(sh *shell, elem gwda.WebElement, args []string) (interface{}, error)
+}
+
+func noArgs(fn func(elem gwda.WebElement) (interface{}, error)) func(sh *shell, elem gwda.WebElement, args []string) (interface{}, error) {
+	return func(sh *shell, elem gwda.WebElement, args []string) (interface{}, error) {
+		if len(args) != 0 {
+			return nil, errUsage
+		}
+		return fn(elem)
+	}
+}
+
+var elementMethods = map[string]elementMethod{
+	"click":           {"", noArgs(func(elem gwda.WebElement) (interface{}, error) { return nil, elem.Click() })},
+	"clear":           {"", noArgs(func(elem gwda.WebElement) (interface{}, error) { return nil, elem.Clear() })},
+	"doubletap":       {"", noArgs(func(elem gwda.WebElement) (interface{}, error) { return nil, elem.DoubleTap() })},
+	"scrolltovisible": {"", noArgs(func(elem gwda.WebElement) (interface{}, error) { return nil, elem.ScrollToVisible() })},
+	"text":            {"", noArgs(func(elem gwda.WebElement) (interface{}, error) { return elem.Text() })},
+	"type":            {"", noArgs(func(elem gwda.WebElement) (interface{}, error) { return elem.Type() })},
+	"rect":            {"", noArgs(func(elem gwda.WebElement) (interface{}, error) { return elem.Rect() })},
+	"isenabled":       {"", noArgs(func(elem gwda.WebElement) (interface{}, error) { return elem.IsEnabled() })},
+	"isdisplayed":     {"", noArgs(func(elem gwda.WebElement) (interface{}, error) { return elem.IsDisplayed() })},
+	"isselected":      {"", noArgs(func(elem gwda.WebElement) (interface{}, error) { return elem.IsSelected() })},
+	"uid":             {"", noArgs(func(elem gwda.WebElement) (interface{}, error) { return elem.UID(), nil })},
+	"tap": {"X Y", func(sh *shell, elem gwda.WebElement, args []string) (interface{}, error) {
+		xy, err := parseFloats(args, 2)
+		if err != nil {
+			return nil, err
+		}
+		return nil, elem.TapFloat(xy[0], xy[1])
+	}},
+	"touchandhold": {"[SECONDS]", func(sh *shell, elem gwda.WebElement, args []string) (interface{}, error) {
+		if len(args) == 0 {
+			return nil, elem.TouchAndHold()
+		}
+		seconds, err := parseFloats(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return nil, elem.TouchAndHold(seconds[0])
+	}},
+	"sendkeys": {"TEXT", func(sh *shell, elem gwda.WebElement, args []string) (interface{}, error) {
+		if len(args) == 0 {
+			return nil, errUsage
+		}
+		return nil, elem.SendKeys(strings.Join(args, " "))
+	}},
+	"typetext": {"TEXT", func(sh *shell, elem gwda.WebElement, args []string) (interface{}, error) {
+		if len(args) == 0 {
+			return nil, errUsage
+		}
+		return nil, elem.TypeText(strings.Join(args, " "))
+	}},
+	"swipe": {"up|down|left|right", func(sh *shell, elem gwda.WebElement, args []string) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, errUsage
+		}
+		return nil, elem.SwipeDirection(gwda.Direction(args[0]))
+	}},
+	"attribute": {"NAME", func(sh *shell, elem gwda.WebElement, args []string) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, errUsage
+		}
+		return elem.GetAttribute(gwda.ElementAttribute{args[0]: ""})
+	}},
+	"screenshot": {"FILE", func(sh *shell, elem gwda.WebElement, args []string) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, errUsage
+		}
+		raw, err := elem.Screenshot()
+		if err != nil {
+			return nil, err
+		}
+		return args[0], ioutil.WriteFile(args[0], raw.Bytes(), 0644)
+	}},
+	"selectors": {"", func(sh *shell, elem gwda.WebElement, args []string) (interface{}, error) {
+		if len(args) != 0 {
+			return nil, errUsage
+		}
+		driver, err := sh.e.Driver()
+		if err != nil {
+			return nil, err
+		}
+		selectors, err := gwda.SuggestSelectors(driver, elem)
+		if err != nil {
+			return nil, err
+		}
+		lines := make([]string, len(selectors))
+		for i, by := range selectors {
+			lines[i] = selectorString(by)
+		}
+		return strings.Join(lines, "\n"), nil
+	}},
+	"find": {"STRATEGY VALUE", func(sh *shell, elem gwda.WebElement, args []string) (interface{}, error) {
+		by, err := parseSelector(args)
+		if err != nil {
+			return nil, err
+		}
+		child, err := elem.FindElement(by)
+		if err != nil {
+			return nil, err
+		}
+		return sh.store(child), nil
+	}},
+	"findall": {"STRATEGY VALUE", func(sh *shell, elem gwda.WebElement, args []string) (interface{}, error) {
+		by, err := parseSelector(args)
+		if err != nil {
+			return nil, err
+		}
+		children, err := elem.FindElements(by)
+		if err != nil {
+			return nil, err
+		}
+		return sh.store(children...), nil
+	}},
+}
+
+var shellFilters = []string{"grep", "head", "tail", "wc"}
+
+var shellBuiltins = []string{"exit", "find", "findall", "help", "history", "quit", "record", "time", "vars"}
+
+// shell Runs commands against one session, storing the found elements as `$1`, `$2`, ...
+type shell struct {
+	e    *env
+	out  io.Writer
+	vars map[int]gwda.WebElement
+	// selectors The selectors of the elements found by `find`, which locate them in the recorded code
+	selectors map[int]gwda.BySelector
+	next      int
+	timing    bool
+	editor    *lineEditor
+	recorder  *gwda.ActionRecorder
+}
+
+func newShell(e *env) *shell {
+	sh := &shell{
+		e:         e,
+		out:       e.stdout,
+		vars:      make(map[int]gwda.WebElement),
+		selectors: make(map[int]gwda.BySelector),
+		next:      1,
+		timing:    true,
+		recorder:  gwda.NewActionRecorder(),
+	}
+	return sh
+}
+
+func cmdShell(e *env, args []string) (interface{}, error) {
+	if len(args) != 0 {
+		return nil, errUsage
+	}
+	if _, err := e.Driver(); err != nil {
+		return nil, err
+	}
+	sh := newShell(e)
+
+	var in io.Reader = e.stdin
+	if f, ok := e.stdin.(*os.File); ok {
+		if restore, err := makeRaw(int(f.Fd())); err == nil {
+			defer func() { _ = restore() }()
+			sh.editor = newLineEditor(f, e.stdout, sh.complete)
+			sh.loadHistory()
+			_, _ = fmt.Fprintln(e.stdout, "connected, type `help` for the commands")
+		}
+	}
+	if sh.editor != nil {
+		for {
+			line, err := sh.editor.readLine("gwda> ")
+			if err == errInterrupted {
+				continue
+			}
+			if err != nil {
+				break
+			}
+			sh.editor.addHistory(strings.TrimSpace(line))
+			sh.saveHistory(line)
+			if sh.execLine(line) {
+				break
+			}
+		}
+		return nil, nil
+	}
+
+	// not a terminal, e.g. a script piped into the shell
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if sh.execLine(scanner.Text()) {
+			break
+		}
+	}
+	return nil, scanner.Err()
+}
+
+func historyPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".gwda_history")
+}
+
+func (sh *shell) loadHistory() {
+	path := historyPath()
+	if path == "" {
+		return
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) > 500 {
+		lines = lines[len(lines)-500:]
+	}
+	for _, line := range lines {
+		sh.editor.addHistory(line)
+	}
+}
+
+func (sh *shell) saveHistory(line string) {
+	path := historyPath()
+	if path == "" || strings.TrimSpace(line) == "" {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintln(f, strings.TrimSpace(line))
+	_ = f.Close()
+}
+
+// execLine Runs a line, returning true to quit the shell
+func (sh *shell) execLine(line string) (quit bool) {
+	segments, err := tokenize(line)
+	if err != nil {
+		_, _ = fmt.Fprintf(sh.out, "error: %v\n", err)
+		return false
+	}
+	if len(segments) == 0 || len(segments[0]) == 0 {
+		return false
+	}
+	if name := segments[0][0]; name == "exit" || name == "quit" {
+		return true
+	}
+
+	startTime := time.Now()
+	var buf bytes.Buffer
+	sh.e.stdout = &buf
+	err = sh.exec(segments[0])
+	sh.e.stdout = sh.out
+	output := buf.String()
+	for _, filter := range segments[1:] {
+		if err != nil {
+			break
+		}
+		output, err = applyFilter(filter, output)
+	}
+	_, _ = io.WriteString(sh.out, output)
+	if err != nil {
+		_, _ = fmt.Fprintf(sh.out, "error: %v\n", err)
+	}
+	if sh.timing {
+		_, _ = fmt.Fprintf(sh.out, "(%v)\n", time.Since(startTime).Round(time.Millisecond))
+	}
+	return false
+}
+
+func (sh *shell) exec(args []string) (err error) {
+	name := args[0]
+	var result interface{}
+	switch {
+	case name == "help":
+		if len(args) > 1 {
+			if cmd, ok := commands[args[1]]; ok {
+				return sh.e.print(fmt.Sprintf("usage: %s %s\n  %s", cmd.name, cmd.usage, cmd.summary))
+			}
+			if method, ok := elementMethods[args[1]]; ok {
+				return sh.e.print(fmt.Sprintf("usage: $N.%s %s", args[1], method.usage))
+			}
+		}
+		names := make([]string, 0, len(elementMethods))
+		for name := range elementMethods {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		_, err = fmt.Fprintf(sh.e.stdout, shellHelp, strings.Join(strategyNames(), ", "), strings.Join(names, ", "))
+		return err
+	case name == "vars":
+		ids := make([]int, 0, len(sh.vars))
+		for id := range sh.vars {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		lines := make([]string, len(ids))
+		for i, id := range ids {
+			lines[i] = describe(id, sh.vars[id])
+		}
+		result = strings.Join(lines, "\n")
+	case name == "history":
+		if sh.editor != nil {
+			result = strings.Join(sh.editor.history, "\n")
+		}
+	case name == "time":
+		if len(args) != 2 || (args[1] != "on" && args[1] != "off") {
+			return fmt.Errorf("usage: time on|off")
+		}
+		sh.timing = args[1] == "on"
+	case name == "record":
+		if result, err = sh.record(args[1:]); err != nil {
+			return err
+		}
+	case name == "find" || name == "findall":
+		var by gwda.BySelector
+		if by, err = parseSelector(args[1:]); err != nil {
+			if errors.Is(err, errUsage) {
+				return fmt.Errorf("usage: %s STRATEGY VALUE", name)
+			}
+			return err
+		}
+		var driver gwda.WebDriver
+		if driver, err = sh.e.Driver(); err != nil {
+			return err
+		}
+		if name == "find" {
+			var elem gwda.WebElement
+			if elem, err = driver.FindElement(by); err != nil {
+				return err
+			}
+			result = sh.store(elem)
+			sh.selectors[sh.next-1] = by
+		} else {
+			var elems []gwda.WebElement
+			if elems, err = driver.FindElements(by); err != nil {
+				return err
+			}
+			result = sh.store(elems...)
+		}
+	case strings.HasPrefix(name, "$"):
+		if result, err = sh.callElement(name, args[1:]); err != nil {
+			return err
+		}
+	default:
+		cmd, ok := commands[name]
+		if !ok || name == "shell" {
+			return fmt.Errorf("unknown command %q, type `help` for the commands", name)
+		}
+		if result, err = cmd.run(sh.e, args[1:]); err != nil {
+			if errors.Is(err, errUsage) {
+				return fmt.Errorf("usage: %s %s", cmd.name, cmd.usage)
+			}
+			return err
+		}
+	}
+	return sh.e.print(result)
+}
+
+// callElement Runs `$N` or `$N.method`
+func (sh *shell) callElement(ref string, args []string) (interface{}, error) {
+	varName, methodName := ref[1:], ""
+	if i := strings.Index(varName, "."); i >= 0 {
+		varName, methodName = varName[:i], strings.ToLower(varName[i+1:])
+	}
+	id, err := strconv.Atoi(varName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid variable %q", ref)
+	}
+	elem, ok := sh.vars[id]
+	if !ok {
+		return nil, fmt.Errorf("undefined variable $%d", id)
+	}
+	if methodName == "" {
+		return describe(id, elem), nil
+	}
+	method, ok := elementMethods[methodName]
+	if !ok {
+		return nil, fmt.Errorf("unknown method %q, type `help` for the methods", methodName)
+	}
+	// the action is refused rather than performed unrecorded
+	recording := sh.e.recorder != nil && (methodName == "click" || methodName == "sendkeys")
+	by, ok := sh.selectors[id]
+	if recording && !ok {
+		return nil, fmt.Errorf("not recorded: $%d was not found by `find`, the action is not performed", id)
+	}
+	result, err := method.run(sh, elem, args)
+	if errors.Is(err, errUsage) {
+		return nil, fmt.Errorf("usage: $%d.%s %s", id, methodName, method.usage)
+	}
+	if err != nil || !recording {
+		return result, err
+	}
+	if methodName == "click" {
+		sh.e.recorder.RecordClick(by)
+	} else {
+		sh.e.recorder.RecordElementSendKeys(by, strings.Join(args, " "))
+	}
+	return result, nil
+}
+
+// record Runs `record start|stop|clear|code [FILE]`
+func (sh *shell) record(args []string) (interface{}, error) {
+	usage := fmt.Errorf("usage: record start|stop|clear|code [FILE]")
+	if len(args) == 0 {
+		return nil, usage
+	}
+	switch args[0] {
+	case "start":
+		sh.e.recorder = sh.recorder
+		return fmt.Sprintf("recording, %d actions so far", sh.recorder.Len()), nil
+	case "stop":
+		sh.e.recorder = nil
+		return fmt.Sprintf("stopped, %d actions recorded", sh.recorder.Len()), nil
+	case "clear":
+		sh.recorder.Reset()
+		return nil, nil
+	case "code":
+		if len(args) > 2 {
+			return nil, usage
+		}
+		code, err := sh.recorder.TestFile("main_test", "TestRecorded")
+		if err != nil {
+			return nil, err
+		}
+		if len(args) == 2 {
+			return args[1], ioutil.WriteFile(args[1], code, 0644)
+		}
+		return string(code), nil
+	default:
+		return nil, usage
+	}
+}
+
+// store Stores the elements as the next variables and describes them
+func (sh *shell) store(elems ...gwda.WebElement) string {
+	lines := make([]string, len(elems))
+	for i, elem := range elems {
+		sh.vars[sh.next] = elem
+		lines[i] = describe(sh.next, elem)
+		sh.next++
+	}
+	if len(lines) == 0 {
+		return "no elements"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describe e.g. `$1 = XCUIElementTypeButton "OK"`, errors are ignored as the element may have gone
+func describe(id int, elem gwda.WebElement) string {
+	s := fmt.Sprintf("$%d =", id)
+	if elemType, err := elem.Type(); err == nil {
+		s += " " + elemType
+	}
+	if text, err := elem.Text(); err == nil && text != "" {
+		s += " " + strconv.Quote(text)
+	}
+	return s
+}
+
+// complete Completes commands, variables and their methods, strategies and filters
+func (sh *shell) complete(line string) (start int, candidates []string) {
+	start = strings.LastIndexAny(line, " \t|") + 1
+	word := line[start:]
+	before := strings.Fields(strings.TrimSpace(line[:start]))
+	if i := strings.LastIndex(line[:start], "|"); i >= 0 {
+		before = strings.Fields(line[i+1 : start])
+		if len(before) == 0 {
+			return start, matchPrefix(shellFilters, word)
+		}
+	}
+
+	var names []string
+	switch {
+	case len(before) == 0 && strings.HasPrefix(word, "$") && strings.Contains(word, "."):
+		ref := word[:strings.Index(word, ".")+1]
+		for name := range elementMethods {
+			names = append(names, ref+name)
+		}
+	case len(before) == 0:
+		names = append(names, shellBuiltins...)
+		for name := range commands {
+			if name != "shell" {
+				names = append(names, name)
+			}
+		}
+		for id := range sh.vars {
+			names = append(names, fmt.Sprintf("$%d.", id))
+		}
+	case len(before) == 1 && (before[0] == "find" || before[0] == "findall" ||
+		strings.HasSuffix(before[0], ".find") || strings.HasSuffix(before[0], ".findall")):
+		names = strategyNames()
+	case len(before) == 1 && before[0] == "help":
+		names = commandNames()
+	case len(before) == 1 && before[0] == "record":
+		names = []string{"clear", "code", "start", "stop"}
+	}
+	return start, matchPrefix(names, word)
+}
+
+func matchPrefix(names []string, prefix string) (matches []string) {
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) {
+			matches = append(matches, name)
+		}
+	}
+	sort.Strings(matches)
+	return
+}
+
+func strategyNames() []string {
+	names := make([]string, 0, len(shellStrategies))
+	for name := range shellStrategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyFilter Runs `grep`, `head`, `tail` or `wc` on the output of the previous command
+func applyFilter(args []string, input string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("empty pipe")
+	}
+	lines := strings.SplitAfter(input, "\n")
+	if len(lines) != 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	count := func() (int, error) {
+		if len(args) == 1 {
+			return 10, nil
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || len(args) > 2 {
+			return 0, fmt.Errorf("usage: %s [N]", args[0])
+		}
+		return n, nil
+	}
+
+	switch args[0] {
+	case "grep":
+		invert, pattern := false, ""
+		flags := ""
+		for _, arg := range args[1:] {
+			switch {
+			case arg == "-v":
+				invert = true
+			case arg == "-i":
+				flags = "(?i)"
+			case pattern == "":
+				pattern = arg
+			default:
+				return "", fmt.Errorf("usage: grep [-v] [-i] PATTERN")
+			}
+		}
+		re, err := regexp.Compile(flags + pattern)
+		if err != nil {
+			return "", err
+		}
+		var out strings.Builder
+		for _, line := range lines {
+			if re.MatchString(line) != invert {
+				out.WriteString(line)
+			}
+		}
+		return out.String(), nil
+	case "head":
+		n, err := count()
+		if err != nil {
+			return "", err
+		}
+		if n < len(lines) {
+			lines = lines[:n]
+		}
+		return strings.Join(lines, ""), nil
+	case "tail":
+		n, err := count()
+		if err != nil {
+			return "", err
+		}
+		if n < len(lines) {
+			lines = lines[len(lines)-n:]
+		}
+		return strings.Join(lines, ""), nil
+	case "wc":
+		return fmt.Sprintf("%d\n", len(lines)), nil
+	default:
+		return "", fmt.Errorf("unknown filter %q, expected one of %s", args[0], strings.Join(shellFilters, ", "))
+	}
+}
+
+// tokenize Splits the line into words, honoring quotes and backslashes, and into the segments of a pipe
+func tokenize(line string) (segments [][]string, err error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == '|':
+			endWord()
+			segments = append(segments, words)
+			words = nil
+		case r == ' ' || r == '\t':
+			endWord()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	endWord()
+	if len(words) == 0 && len(segments) == 0 {
+		return nil, nil
+	}
+	segments = append(segments, words)
+	for _, segment := range segments {
+		if len(segment) == 0 {
+			return nil, fmt.Errorf("empty pipe")
+		}
+	}
+	return segments, nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/electricbubble/gwda"
+)
+
+type fakeElement struct {
+	gwda.WebElement
+	elemType string
+	text     string
+	clicked  int
+}
+
+func (we *fakeElement) Type() (string, error) {
+	return we.elemType, nil
+}
+
+func (we *fakeElement) Text() (string, error) {
+	return we.text, nil
+}
+
+func (we *fakeElement) Click() error {
+	we.clicked++
+	return nil
+}
+
+type fakeShellDriver struct {
+	fakeDriver
+	elements []gwda.WebElement
+	by       gwda.BySelector
+}
+
+func (wd *fakeShellDriver) FindElement(by gwda.BySelector) (gwda.WebElement, error) {
+	wd.by = by
+	return wd.elements[0], nil
+}
+
+func (wd *fakeShellDriver) FindElements(by gwda.BySelector) ([]gwda.WebElement, error) {
+	wd.by = by
+	return wd.elements, nil
+}
+
+func (wd *fakeShellDriver) Source(srcOpt ...gwda.SourceOption) (string, error) {
+	return "<XCUIElementTypeApplication>\n<XCUIElementTypeButton name=\"OK\"/>\n<XCUIElementTypeButton name=\"Cancel\"/>\n</XCUIElementTypeApplication>", nil
+}
+
+func TestTokenize(t *testing.T) {
+	segments, err := tokenize(`find predicate "label == 'OK'" | grep -i 'a b' | head 2`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]string{{"find", "predicate", "label == 'OK'"}, {"grep", "-i", "a b"}, {"head", "2"}}
+	if !reflect.DeepEqual(segments, expected) {
+		t.Fatalf("expected %q, got %q", expected, segments)
+	}
+	if segments, _ = tokenize(`type a\ b "" "x|y"`); !reflect.DeepEqual(segments, [][]string{{"type", "a b", "", "x|y"}}) {
+		t.Fatalf("unexpected segments: %q", segments)
+	}
+	for _, line := range []string{`find "x`, `source |`, `| grep x`, `tap 1\`} {
+		if _, err = tokenize(line); err == nil {
+			t.Fatalf("expected an error for %q", line)
+		}
+	}
+}
+
+func TestShell(t *testing.T) {
+	ok := &fakeElement{elemType: "XCUIElementTypeButton", text: "OK"}
+	cancel := &fakeElement{elemType: "XCUIElementTypeButton", text: "Cancel"}
+	wd := &fakeShellDriver{elements: []gwda.WebElement{ok, cancel}}
+	e, _ := newTestEnv(&wd.fakeDriver, false)
+	e.connect = func(opts options) (gwda.WebDriver, error) { return wd, nil }
+	out := new(bytes.Buffer)
+	e.stdin = strings.NewReader(`find predicate "label == 'OK'"
+$1.click
+findall classchain **/XCUIElementTypeButton
+$3
+$3.text
+$4.nope
+source | grep -v Application | head 1
+vars | wc
+exit
+tap 1 1
+`)
+	e.stdout = out
+	if _, err := cmdShell(e, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `$1 = XCUIElementTypeButton "OK"
+$2 = XCUIElementTypeButton "OK"
+$3 = XCUIElementTypeButton "Cancel"
+$3 = XCUIElementTypeButton "Cancel"
+Cancel
+error: undefined variable $4
+<XCUIElementTypeButton name="OK"/>
+3
+`
+	// the timing of each command is printed on its own line
+	var lines []string
+	for _, line := range strings.SplitAfter(out.String(), "\n") {
+		if !strings.HasPrefix(line, "(") {
+			lines = append(lines, line)
+		}
+	}
+	if got := strings.Join(lines, ""); got != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+	if ok.clicked != 1 || wd.by.ClassChain != "**/XCUIElementTypeButton" {
+		t.Fatalf("unexpected calls: %d %+v", ok.clicked, wd.by)
+	}
+	if len(wd.calls) != 0 {
+		t.Fatal("expected the shell to stop at exit")
+	}
+}
+
+func TestShellRecord(t *testing.T) {
+	ok := &fakeElement{elemType: "XCUIElementTypeButton", text: "OK"}
+	wd := &fakeShellDriver{elements: []gwda.WebElement{ok}}
+	e, _ := newTestEnv(&wd.fakeDriver, false)
+	e.connect = func(opts options) (gwda.WebDriver, error) { return wd, nil }
+	out := new(bytes.Buffer)
+	e.stdin = strings.NewReader(`time off
+tap 1 1
+record start
+find name OK
+$1.click
+findall name OK
+$2.click
+tap 10 20.5
+alert accept Allow
+record stop
+tap 3 3
+record code
+`)
+	e.stdout = out
+	if _, err := cmdShell(e, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"recording, 0 actions so far\n",
+		"error: not recorded: $2 was not found by `find`, the action is not performed\n",
+		"stopped, 3 actions recorded\n",
+		`driver.FindElement(gwda.BySelector{Name: "OK"})`,
+		"if err = elem.Click(); err != nil {",
+		"if err = driver.TapFloat(10.0, 20.5); err != nil {",
+		`if err = driver.AlertAccept("Allow"); err != nil {`,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Fatalf("expected %q in:\n%s", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "TapFloat(1.0") || strings.Contains(out.String(), "TapFloat(3.0") {
+		t.Fatalf("unexpected taps recorded:\n%s", out.String())
+	}
+	if ok.clicked != 1 || len(wd.calls) != 4 {
+		t.Fatalf("unexpected calls: %d %q", ok.clicked, wd.calls)
+	}
+}
+
+func TestShellComplete(t *testing.T) {
+	sh := newShell(&env{})
+	sh.vars[1] = &fakeElement{}
+	for line, expected := range map[string][]string{
+		"scr":              {"screenshot"},
+		"$":                {"$1."},
+		"$1.is":            {"$1.isdisplayed", "$1.isenabled", "$1.isselected"},
+		"find pre":         {"predicate"},
+		"$1.find x":        {"xpath"},
+		"source | g":       {"grep"},
+		"tap 1 ":           nil,
+		"help sw":          {"swipe"},
+		"vars | head 1 | ": shellFilters,
+	} {
+		_, candidates := sh.complete(line)
+		if !reflect.DeepEqual(candidates, expected) {
+			t.Errorf("%q: expected %q, got %q", line, expected, candidates)
+		}
+	}
+}
+
+func TestLineEditor(t *testing.T) {
+	input := "tap 1\x1b[D2\r" + // insert before the last rune
+		"\x1b[A\x1b[A\x1b[B\r" + // recall the last line
+		"scr\tx\r" + // complete the command
+		"abc\x7f\x7f\x03" + // erase and interrupt
+		"\x04"
+	out := new(bytes.Buffer)
+	le := newLineEditor(strings.NewReader(input), out, newShell(&env{}).complete)
+	le.addHistory("status")
+
+	var lines []string
+	for {
+		line, err := le.readLine("> ")
+		if err == errInterrupted {
+			lines = append(lines, "^C")
+			continue
+		}
+		if err != nil {
+			break
+		}
+		le.addHistory(line)
+		lines = append(lines, line)
+	}
+	expected := []string{"tap 21", "tap 21", "screenshot x", "^C"}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("expected %q, got %q", expected, lines)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package main
+
+import "errors"
+
+// makeRaw Raw mode is not supported, the shell reads whole lines without editing
+func makeRaw(fd int) (restore func() error, err error) {
+	return nil, errors.New("raw mode is not supported on this platform")
+}
@@ -0,0 +1,38 @@
+//go:build linux || darwin
+// +build linux darwin
+
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+func ioctlTermios(fd int, req uintptr, t *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, uintptr(unsafe.Pointer(t))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// makeRaw Puts the terminal into raw mode, so that keys are read one by one without echo.
+// It fails if the fd is not a terminal.
+func makeRaw(fd int) (restore func() error, err error) {
+	var saved syscall.Termios
+	if err = ioctlTermios(fd, ioctlGetTermios, &saved); err != nil {
+		return nil, err
+	}
+	raw := saved
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err = ioctlTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, err
+	}
+	return func() error {
+		return ioctlTermios(fd, ioctlSetTermios, &saved)
+	}, nil
+}
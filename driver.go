@@ -6,10 +6,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"image"
 	"net"
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -32,10 +34,13 @@ func NewDriver(capabilities Capabilities, urlPrefix string, mjpegPort ...int) (d
 	}
 	wd.sessionId = sessionInfo.SessionId
 
-	if wd.mjpegConn, err = net.Dial("tcp", fmt.Sprintf("%s:%d", wd.urlPrefix.Hostname(), mjpegPort[0])); err != nil {
+	mjpegAddress := net.JoinHostPort(wd.urlPrefix.Hostname(), strconv.Itoa(mjpegPort[0]))
+	if wd.mjpegConn, err = net.Dial("tcp", mjpegAddress); err != nil {
 		return nil, err
 	}
-	wd.mjpegClient = convertToHTTPClient(wd.mjpegConn)
+	wd.mjpegClient = convertToRedialHTTPClient(wd.mjpegConn, func() (net.Conn, error) {
+		return net.Dial("tcp", mjpegAddress)
+	})
 
 	return wd, nil
 }
@@ -67,7 +72,13 @@ func NewUSBDriver(capabilities Capabilities, device ...Device) (driver WebDriver
 	if wd.usbCli.mjpegConn, err = dev.d.NewConnect(dev.MjpegPort, 0); err != nil {
 		return nil, fmt.Errorf("create connection MJPEG: %w", err)
 	}
-	wd.mjpegClient = convertToHTTPClient(wd.usbCli.mjpegConn.RawConn())
+	wd.mjpegClient = convertToRedialHTTPClient(wd.usbCli.mjpegConn.RawConn(), func() (net.Conn, error) {
+		conn, err := dev.d.NewConnect(dev.MjpegPort, 0)
+		if err != nil {
+			return nil, fmt.Errorf("create connection MJPEG: %w", err)
+		}
+		return conn.RawConn(), nil
+	})
 
 	if wd.urlPrefix, err = url.Parse("http://" + dev.serialNumber); err != nil {
 		return nil, err
@@ -114,6 +125,7 @@ func (wd *remoteWD) executeGet(pathElem ...string) (rawResp rawResponse, err err
 }
 
 func (wd *remoteWD) executePost(data interface{}, pathElem ...string) (rawResp rawResponse, err error) {
+	wd.runAlertHook(pathElem)
 	var bsJSON []byte = nil
 	if data != nil {
 		if bsJSON, err = json.Marshal(data); err != nil {
@@ -139,11 +151,83 @@ func (wd *remoteWD) executeDelete(pathElem ...string) (rawResp rawResponse, err
 	return executeHTTP(http.MethodDelete, wd._requestURL(nil, pathElem...), nil, httpCli)
 }
 
+func (wd *remoteWD) setAlertHook(hook func()) {
+	wd.alertHookMu.Lock()
+	defer wd.alertHookMu.Unlock()
+	wd.alertHook = hook
+}
+
+// runAlertHook Lets the attached AlertMonitor handle alerts before the action, but the alert actions themselves
+func (wd *remoteWD) runAlertHook(pathElem []string) {
+	wd.alertHookMu.Lock()
+	hook := wd.alertHook
+	wd.alertHookMu.Unlock()
+	if hook == nil || strings.Contains(path.Join(pathElem...), "/alert/") {
+		return
+	}
+	hook()
+}
+
 func (wd *remoteWD) GetMjpegHTTPClient() *http.Client {
 	return wd.mjpegClient
 }
 
+func (wd *remoteWD) StartRecording(path string, opts ...RecordingOption) (err error) {
+	wd.recordingMu.Lock()
+	defer wd.recordingMu.Unlock()
+	if wd.recorder != nil {
+		return errors.New("recording is already in progress")
+	}
+	wd.recorder, err = NewScreenRecorder(wd, path, opts...)
+	return
+}
+
+func (wd *remoteWD) StopRecording() (info RecordingInfo, err error) {
+	wd.recordingMu.Lock()
+	defer wd.recordingMu.Unlock()
+	if wd.recorder == nil {
+		return RecordingInfo{}, errors.New("no recording in progress")
+	}
+	info, err = wd.recorder.Stop()
+	wd.recorder = nil
+	return
+}
+
+// trackMjpegStream Closes the stream on Close, unless it is closed before
+func (wd *remoteWD) trackMjpegStream(s *MjpegStream) {
+	wd.mjpegStreamsMu.Lock()
+	defer wd.mjpegStreamsMu.Unlock()
+	if wd.mjpegStreams == nil {
+		wd.mjpegStreams = make(map[*MjpegStream]struct{})
+	}
+	wd.mjpegStreams[s] = struct{}{}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onClose = func() {
+		wd.mjpegStreamsMu.Lock()
+		defer wd.mjpegStreamsMu.Unlock()
+		delete(wd.mjpegStreams, s)
+	}
+}
+
+func (wd *remoteWD) closeMjpegStreams() {
+	wd.mjpegStreamsMu.Lock()
+	streams := make([]*MjpegStream, 0, len(wd.mjpegStreams))
+	for s := range wd.mjpegStreams {
+		streams = append(streams, s)
+	}
+	wd.mjpegStreamsMu.Unlock()
+	for _, s := range streams {
+		_ = s.Close()
+	}
+}
+
 func (wd *remoteWD) Close() error {
+	_, _ = wd.StopRecording()
+	// after the recording, which finalizes its file from its own stream
+	wd.closeMjpegStreams()
+
 	if wd.usbCli == nil {
 		wd.mjpegClient.CloseIdleConnections()
 		return wd.mjpegConn.Close()
@@ -173,6 +257,17 @@ type remoteWD struct {
 
 	mjpegClient *http.Client
 	mjpegConn   net.Conn
+
+	recorder    *ScreenRecorder
+	recordingMu sync.Mutex
+
+	// mjpegStreams The streams of NewMjpegStream, closed by Close
+	mjpegStreams   map[*MjpegStream]struct{}
+	mjpegStreamsMu sync.Mutex
+
+	// alertHook Runs before the actions, see AlertMonitor.Attach
+	alertHook   func()
+	alertHookMu sync.Mutex
 }
 
 func (wd *remoteWD) NewSession(capabilities Capabilities) (sessionInfo SessionInfo, err error) {
@@ -436,7 +531,7 @@ func (wd *remoteWD) AlertDismiss(label ...string) (err error) {
 
 func (wd *remoteWD) AlertSendKeys(text string) (err error) {
 	// [[FBRoute POST:@"/alert/text"] respondWithTarget:self action:@selector(handleAlertSetTextCommand:)]
-	data := map[string]interface{}{"value": strings.Split(text, "")}
+	data := map[string]interface{}{"value": splitGraphemes(text)}
 	_, err = wd.executePost(data, "/session", wd.sessionId, "/alert/text")
 	return
 }
@@ -586,8 +681,19 @@ func (wd *remoteWD) ForceTouchFloat(x, y, pressure float64, second ...float64) e
 	return wd.PerformAppiumTouchActions(actions)
 }
 
+func (wd *remoteWD) ScrollUntil(container WebElement, by BySelector, direction Direction, maxSwipes int, opts ...ScrollUntilOption) (element WebElement, err error) {
+	var su *scrollUntil
+	if su, err = newScrollUntil(wd, container, by, opts...); err != nil {
+		return nil, err
+	}
+	return su.run(direction, maxSwipes)
+}
+
 func (wd *remoteWD) PerformW3CActions(actions *W3CActions) (err error) {
 	// [[FBRoute POST:@"/actions"] respondWithTarget:self action:@selector(handlePerformW3CTouchActions:)]
+	if err = actions.Validate(); err != nil {
+		return fmt.Errorf("invalid actions: %w", err)
+	}
 	data := map[string]interface{}{"actions": actions}
 	_, err = wd.executePost(data, "/session", wd.sessionId, "/actions")
 	return
@@ -626,7 +732,7 @@ func (wd *remoteWD) GetPasteboard(contentType PasteboardType) (raw *bytes.Buffer
 
 func (wd *remoteWD) SendKeys(text string, frequency ...int) (err error) {
 	// [[FBRoute POST:@"/wda/keys"] respondWithTarget:self action:@selector(handleKeys:)]
-	data := map[string]interface{}{"value": strings.Split(text, "")}
+	data := map[string]interface{}{"value": splitGraphemes(text)}
 	if len(frequency) == 0 || frequency[0] <= 0 {
 		frequency = []int{60}
 	}
@@ -817,6 +923,34 @@ func (wd *remoteWD) Screenshot() (raw *bytes.Buffer, err error) {
 	return
 }
 
+func (wd *remoteWD) ScreenshotImage() (screenshot DecodedScreenshot, err error) {
+	var raw *bytes.Buffer
+	if raw, err = wd.Screenshot(); err != nil {
+		return DecodedScreenshot{}, err
+	}
+	if screenshot.Image, screenshot.Format, err = image.Decode(raw); err != nil {
+		return DecodedScreenshot{}, fmt.Errorf("decode screenshot: %w", err)
+	}
+
+	if screenshot.PointSize, err = wd.WindowSize(); err != nil {
+		return DecodedScreenshot{}, err
+	}
+	var screen Screen
+	if screen, err = wd.Screen(); err != nil {
+		return DecodedScreenshot{}, err
+	}
+	screenshot.Scale = screen.Scale
+	screenshot.StatusBarSize = screen.StatusBarSize
+	if screenshot.Orientation, err = wd.Orientation(); err != nil {
+		return DecodedScreenshot{}, err
+	}
+
+	screenshot.Image = uprightScreenshot(screenshot.Image, screenshot.Orientation, screenshot.PointSize)
+	bounds := screenshot.Image.Bounds()
+	screenshot.PixelSize = Size{Width: bounds.Dx(), Height: bounds.Dy()}
+	return
+}
+
 func (wd *remoteWD) Source(srcOpt ...SourceOption) (source string, err error) {
 	// [[FBRoute GET:@"/source"] respondWithTarget:self action:@selector(handleGetSourceCommand:)]
 	// [[FBRoute GET:@"/source"].withoutSession
@@ -5,8 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"image"
+	"image/png"
 	"math"
-	"strings"
 )
 
 type remoteWE struct {
@@ -22,7 +23,7 @@ func (we remoteWE) Click() (err error) {
 
 func (we remoteWE) SendKeys(text string, frequency ...int) (err error) {
 	// [[FBRoute POST:@"/element/:uuid/value"] respondWithTarget:self action:@selector(handleSetValue:)]
-	data := map[string]interface{}{"value": strings.Split(text, "")}
+	data := map[string]interface{}{"value": splitGraphemes(text)}
 	if len(frequency) == 0 || frequency[0] <= 0 {
 		frequency = []int{60}
 	}
@@ -31,6 +32,10 @@ func (we remoteWE) SendKeys(text string, frequency ...int) (err error) {
 	return
 }
 
+func (we remoteWE) TypeText(text string, opts ...TypeTextOption) error {
+	return newTypeText(we.parent, we, opts...).run(text)
+}
+
 func (we remoteWE) Clear() (err error) {
 	// [[FBRoute POST:@"/element/:uuid/clear"] respondWithTarget:self action:@selector(handleClear:)]
 	_, err = we.parent.executePost(nil, "/session", we.parent.sessionId, "/element", we.id, "/clear")
@@ -326,6 +331,10 @@ func (we remoteWE) FindVisibleCells() (elements []WebElement, err error) {
 	return
 }
 
+func (we remoteWE) IterateCells(direction Direction, opts ...CellIteratorOption) *CellIterator {
+	return newCellIterator(we, direction, opts...)
+}
+
 func (we remoteWE) Rect() (rect Rect, err error) {
 	// [[FBRoute GET:@"/element/:uuid/rect"] respondWithTarget:self action:@selector(handleGetRect:)]
 	var rawResp rawResponse
@@ -463,10 +472,34 @@ func (we remoteWE) Screenshot() (raw *bytes.Buffer, err error) {
 	// [[FBRoute GET:@"/screenshot/:uuid"] respondWithTarget:self action:@selector(handleElementScreenshot:)]
 	var rawResp rawResponse
 	if rawResp, err = we.parent.executeGet("/session", we.parent.sessionId, "/element", we.id, "/screenshot"); err != nil {
-		return nil, err
+		// the endpoint fails for some element types and iOS versions
+		img, e := newElementScreenshot().crop(we.parent, we)
+		if e != nil {
+			return nil, fmt.Errorf("element screenshot: %v, crop screenshot: %w", err, e)
+		}
+		raw = new(bytes.Buffer)
+		if err = png.Encode(raw, img); err != nil {
+			return nil, err
+		}
+		return raw, nil
 	}
 	if raw, err = rawResp.valueDecodeAsBase64(); err != nil {
 		return nil, err
 	}
 	return
 }
+
+func (we remoteWE) ScreenshotImage(opts ...ElementScreenshotOption) (img image.Image, err error) {
+	es := newElementScreenshot(opts...)
+	if !es.needsCrop() {
+		var raw *bytes.Buffer
+		if raw, err = we.Screenshot(); err != nil {
+			return nil, err
+		}
+		if img, _, err = image.Decode(raw); err != nil {
+			return nil, fmt.Errorf("decode screenshot: %w", err)
+		}
+		return img, nil
+	}
+	return es.crop(we.parent, we)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/electricbubble/gwda"
+	"log"
+	"regexp"
+)
+
+func main() {
+	driver, err := gwda.NewUSBDriver(nil)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer func() { _ = driver.Close() }()
+
+	monitor := gwda.NewAlertMonitor(driver, gwda.WithAlertMonitorRules(
+		gwda.AlertRule{Name: "notifications", TextMatches: regexp.MustCompile(`Send You Notifications`), Tap: "Allow"},
+		gwda.AlertRule{Name: "not now", ButtonsContain: "Not Now", Action: gwda.AlertActionDismiss},
+	))
+	// handles the alerts before every action of the driver
+	if err = monitor.Attach(); err != nil {
+		log.Fatalln(err)
+	}
+	defer monitor.Detach()
+
+	if err = driver.AppLaunch("com.apple.Preferences"); err != nil {
+		log.Fatalln(err)
+	}
+
+	// leaves the alerts to the test
+	monitor.Pause()
+	text, err := driver.AlertText()
+	log.Println(text, err)
+	monitor.Resume()
+
+	for _, alert := range monitor.Handled() {
+		log.Printf("%s: %s on %q", alert.Rule, alert.Action, alert.Text)
+	}
+}
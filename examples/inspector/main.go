@@ -0,0 +1,17 @@
+package main
+
+import (
+	"github.com/electricbubble/gwda"
+	"log"
+)
+
+func main() {
+	driver, err := gwda.NewUSBDriver(nil)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer func() { _ = driver.Close() }()
+
+	log.Println("inspector: http://127.0.0.1:8200")
+	log.Fatalln(gwda.NewInspector(driver).ListenAndServe("127.0.0.1:8200"))
+}
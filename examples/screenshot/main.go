@@ -2,7 +2,6 @@ package main
 
 import (
 	"github.com/electricbubble/gwda"
-	"image"
 	"log"
 )
 
@@ -12,27 +11,28 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	screenshot, err := driver.Screenshot()
+	screenshot, err := driver.ScreenshotImage()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	img, format, err := image.Decode(screenshot)
-	if err != nil {
-		log.Fatal(err)
-	}
-	_, _ = img, format
+	log.Println(screenshot.Format, screenshot.PixelSize, screenshot.PointSize, screenshot.Scale, screenshot.Orientation)
+
+	// the center of the image in points
+	x, y := screenshot.PixelToPoint(screenshot.PixelSize.Width/2, screenshot.PixelSize.Height/2)
+	log.Println(x, y)
+
 	// userHomeDir, _ := os.UserHomeDir()
-	// file, err := os.Create(userHomeDir + "/Desktop/s1." + format)
+	// file, err := os.Create(userHomeDir + "/Desktop/s1." + screenshot.Format)
 	// if err != nil {
 	// 	log.Fatal(err)
 	// }
 	// defer func() { _ = file.Close() }()
-	// switch format {
+	// switch screenshot.Format {
 	// case "png":
-	// 	err = png.Encode(file, img)
+	// 	err = png.Encode(file, screenshot.Image)
 	// case "jpeg":
-	// 	err = jpeg.Encode(file, img, nil)
+	// 	err = jpeg.Encode(file, screenshot.Image, nil)
 	// }
 	// if err != nil {
 	// 	log.Fatal(err)
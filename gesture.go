@@ -0,0 +1,173 @@
+package gwda
+
+import (
+	"math"
+)
+
+// PathPoint A point of a gesture path.
+//  Duration: The time in seconds to move from the previous point to this point
+type PathPoint struct {
+	X, Y     float64
+	Duration float64
+}
+
+const (
+	// gestureFingerSpacing The distance between two fingers of a multi-finger gesture
+	gestureFingerSpacing = 40.0
+	// gestureArcStep The maximum angle in radians covered by a single move of a rotation
+	gestureArcStep = math.Pi / 12
+	// gestureCurveSegments The number of moves a curved swipe is divided into
+	gestureCurveSegments = 16
+)
+
+func newFingerMoveFloat(x, y, second float64, element ...WebElement) FingerMove {
+	fm := NewFingerMove().WithXYFloat(x, y)
+	if second > 0 {
+		fm.WithDuration(second)
+	}
+	if len(element) != 0 {
+		fm.WithOrigin(element[0])
+	}
+	return fm
+}
+
+// fingerPath touches down at the first point, moves through the others and lifts.
+func fingerPath(points []PathPoint, element ...WebElement) *FingerAction {
+	fingerAction := NewFingerAction(len(points) + 2)
+	for i := range points {
+		fingerAction.Move(newFingerMoveFloat(points[i].X, points[i].Y, points[i].Duration, element...))
+		if i == 0 {
+			fingerAction.Down()
+		}
+	}
+	return fingerAction.Up()
+}
+
+// SwipePath Touches down at the first point, moves along the polyline with the duration of each segment and lifts.
+func (act *W3CActions) SwipePath(points []PathPoint, element ...WebElement) *W3CActions {
+	if len(points) == 0 {
+		return act
+	}
+	return act.FingerAction(fingerPath(points, element...))
+}
+
+// SwipeCurve Swipes along a quadratic bezier curve through the control point.
+//  second: The duration of the whole swipe
+func (act *W3CActions) SwipeCurve(fromX, fromY, ctrlX, ctrlY, toX, toY, second float64, element ...WebElement) *W3CActions {
+	points := make([]PathPoint, 0, gestureCurveSegments+1)
+	for i := 0; i <= gestureCurveSegments; i++ {
+		t := float64(i) / gestureCurveSegments
+		x := (1-t)*(1-t)*fromX + 2*(1-t)*t*ctrlX + t*t*toX
+		y := (1-t)*(1-t)*fromY + 2*(1-t)*t*ctrlY + t*t*toY
+		var duration float64
+		if i != 0 {
+			duration = second / gestureCurveSegments
+		}
+		points = append(points, PathPoint{X: x, Y: y, Duration: duration})
+	}
+	return act.SwipePath(points, element...)
+}
+
+// Fling Swipes from one coordinate to another at the given velocity and lifts the finger while moving.
+//  velocity: points per second
+func (act *W3CActions) Fling(fromX, fromY, toX, toY, velocity float64, element ...WebElement) *W3CActions {
+	if velocity <= 0 {
+		velocity = 2000
+	}
+	second := math.Hypot(toX-fromX, toY-fromY) / velocity
+	return act.SwipePath([]PathPoint{{X: fromX, Y: fromY}, {X: toX, Y: toY, Duration: second}}, element...)
+}
+
+// MultiFingerTap Taps with several fingers placed side by side around the coordinate.
+//  fingers: The number of fingers, in range [1, 5]
+func (act *W3CActions) MultiFingerTap(x, y float64, fingers int, element ...WebElement) *W3CActions {
+	if fingers < 1 {
+		fingers = 1
+	} else if fingers > 5 {
+		fingers = 5
+	}
+	left := x - gestureFingerSpacing*float64(fingers-1)/2
+	fActs := make([]*FingerAction, fingers)
+	for i := range fActs {
+		fActs[i] = NewFingerAction().
+			Move(newFingerMoveFloat(left+gestureFingerSpacing*float64(i), y, 0, element...)).
+			Down().
+			Pause(0.1).
+			Up()
+	}
+	return act.FingerAction(fActs[0], fActs[1:]...)
+}
+
+// Pinch Sends a pinching gesture with two fingers placed horizontally about the center.
+// Use `toRadius` less than `fromRadius` to "pinch close" or zoom out,
+// and greater than `fromRadius` to "pinch open" or zoom in.
+//  fromRadius, toRadius: The distance of each finger from the center at the start and the end
+//  second: The duration of the movement
+func (act *W3CActions) Pinch(centerX, centerY, fromRadius, toRadius, second float64, element ...WebElement) *W3CActions {
+	left := fingerPath([]PathPoint{
+		{X: centerX - fromRadius, Y: centerY},
+		{X: centerX - toRadius, Y: centerY, Duration: second},
+	}, element...)
+	right := fingerPath([]PathPoint{
+		{X: centerX + fromRadius, Y: centerY},
+		{X: centerX + toRadius, Y: centerY, Duration: second},
+	}, element...)
+	return act.FingerAction(left, right)
+}
+
+// PinchIn Zooms out by moving two fingers towards the center.
+func (act *W3CActions) PinchIn(centerX, centerY, radius, second float64, element ...WebElement) *W3CActions {
+	return act.Pinch(centerX, centerY, radius, radius/4, second, element...)
+}
+
+// PinchOut Zooms in by moving two fingers away from the center.
+func (act *W3CActions) PinchOut(centerX, centerY, radius, second float64, element ...WebElement) *W3CActions {
+	return act.Pinch(centerX, centerY, radius/4, radius, second, element...)
+}
+
+// TwoFingerRotate Sends a rotation gesture with two fingers on opposite ends of a circle about the center.
+//  rotation: The rotation in radians, positive values rotate clockwise
+//  second: The duration of the movement
+func (act *W3CActions) TwoFingerRotate(centerX, centerY, radius, rotation, second float64, element ...WebElement) *W3CActions {
+	steps := int(math.Ceil(math.Abs(rotation) / gestureArcStep))
+	if steps == 0 {
+		steps = 1
+	}
+	arc := func(startAngle float64) []PathPoint {
+		points := make([]PathPoint, 0, steps+1)
+		for i := 0; i <= steps; i++ {
+			angle := startAngle + rotation*float64(i)/float64(steps)
+			var duration float64
+			if i != 0 {
+				duration = second / float64(steps)
+			}
+			points = append(points, PathPoint{
+				X:        centerX + radius*math.Cos(angle),
+				Y:        centerY + radius*math.Sin(angle),
+				Duration: duration,
+			})
+		}
+		return points
+	}
+	return act.FingerAction(fingerPath(arc(math.Pi), element...), fingerPath(arc(0), element...))
+}
+
+// TwoFingerScroll Moves two fingers side by side from one coordinate to another.
+//  second: The duration of the movement
+func (act *W3CActions) TwoFingerScroll(fromX, fromY, toX, toY, second float64, element ...WebElement) *W3CActions {
+	// place the fingers perpendicular to the movement
+	offsetX, offsetY := gestureFingerSpacing/2, 0.0
+	if distance := math.Hypot(toX-fromX, toY-fromY); distance != 0 {
+		offsetX = -(toY - fromY) / distance * gestureFingerSpacing / 2
+		offsetY = (toX - fromX) / distance * gestureFingerSpacing / 2
+	}
+	left := fingerPath([]PathPoint{
+		{X: fromX - offsetX, Y: fromY - offsetY},
+		{X: toX - offsetX, Y: toY - offsetY, Duration: second},
+	}, element...)
+	right := fingerPath([]PathPoint{
+		{X: fromX + offsetX, Y: fromY + offsetY},
+		{X: toX + offsetX, Y: toY + offsetY, Duration: second},
+	}, element...)
+	return act.FingerAction(left, right)
+}
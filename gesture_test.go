@@ -0,0 +1,73 @@
+package gwda
+
+import (
+	"math"
+	"testing"
+)
+
+func fingerActions(t *testing.T, act *W3CActions) []FingerAction {
+	t.Helper()
+	fActs := make([]FingerAction, len(*act))
+	for i, source := range *act {
+		if source["type"] != "pointer" {
+			t.Fatalf("source %d: unexpected type %v", i, source["type"])
+		}
+		fActs[i] = source["actions"].(FingerAction)
+	}
+	for i := range fActs {
+		if len(fActs[i]) != len(fActs[0]) {
+			t.Fatalf("finger %d: %d actions, expected %d", i, len(fActs[i]), len(fActs[0]))
+		}
+	}
+	return fActs
+}
+
+func lastMove(fAct FingerAction) map[string]interface{} {
+	for i := len(fAct) - 1; i >= 0; i-- {
+		if fAct[i]["type"] == "pointerMove" {
+			return fAct[i]
+		}
+	}
+	return nil
+}
+
+func TestW3CActions_Pinch(t *testing.T) {
+	fActs := fingerActions(t, NewW3CActions().PinchOut(200, 300, 100, 0.5))
+	if len(fActs) != 2 {
+		t.Fatalf("expected 2 fingers, got %d", len(fActs))
+	}
+	if move := lastMove(fActs[0]); move["x"] != 100.0 || move["y"] != 300.0 || move["duration"] != 500.0 {
+		t.Fatalf("unexpected move %v", move)
+	}
+	if move := lastMove(fActs[1]); move["x"] != 300.0 {
+		t.Fatalf("unexpected move %v", move)
+	}
+}
+
+func TestW3CActions_TwoFingerRotate(t *testing.T) {
+	fActs := fingerActions(t, NewW3CActions().TwoFingerRotate(100, 100, 50, math.Pi/2, 1))
+	move := lastMove(fActs[1])
+	if math.Abs(move["x"].(float64)-100) > 1e-9 || math.Abs(move["y"].(float64)-150) > 1e-9 {
+		t.Fatalf("unexpected move %v", move)
+	}
+}
+
+func TestW3CActions_MultiFingerTap(t *testing.T) {
+	fActs := fingerActions(t, NewW3CActions().MultiFingerTap(100, 100, 3))
+	if len(fActs) != 3 {
+		t.Fatalf("expected 3 fingers, got %d", len(fActs))
+	}
+	if fActs[0][0]["x"] != 60.0 || fActs[2][0]["x"] != 140.0 {
+		t.Fatalf("unexpected fingers %v", fActs)
+	}
+}
+
+func TestW3CActions_Fling(t *testing.T) {
+	fActs := fingerActions(t, NewW3CActions().Fling(0, 0, 0, 1000, 4000))
+	if move := lastMove(fActs[0]); move["duration"] != 250.0 {
+		t.Fatalf("unexpected move %v", move)
+	}
+	if last := fActs[0][len(fActs[0])-1]; last["type"] != "pointerUp" {
+		t.Fatalf("expected to lift while moving, got %v", last)
+	}
+}
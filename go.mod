@@ -4,5 +4,6 @@ go 1.16
 
 require (
 	github.com/electricbubble/gidevice v0.6.2
+	gopkg.in/yaml.v3 v3.0.1
 	howett.net/plist v1.0.0 // indirect
 )
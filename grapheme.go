@@ -0,0 +1,155 @@
+package gwda
+
+import (
+	"unicode"
+)
+
+// graphemeClass The Grapheme_Cluster_Break property, see https://unicode.org/reports/tr29/
+type graphemeClass int
+
+const (
+	gcOther graphemeClass = iota
+	gcCR
+	gcLF
+	gcControl
+	gcExtend
+	gcZWJ
+	gcRegionalIndicator
+	gcSpacingMark
+	gcL
+	gcV
+	gcT
+	gcLV
+	gcLVT
+	gcExtendedPictographic
+)
+
+// extendedPictographic An approximation of the Extended_Pictographic property
+var extendedPictographic = [][2]rune{
+	{0x00A9, 0x00A9}, {0x00AE, 0x00AE}, {0x203C, 0x203C}, {0x2049, 0x2049},
+	{0x2122, 0x2122}, {0x2139, 0x2139}, {0x2194, 0x2199}, {0x21A9, 0x21AA},
+	{0x231A, 0x231B}, {0x2328, 0x2328}, {0x2388, 0x2388}, {0x23CF, 0x23CF},
+	{0x23E9, 0x23F3}, {0x23F8, 0x23FA}, {0x24C2, 0x24C2}, {0x25AA, 0x25AB},
+	{0x25B6, 0x25B6}, {0x25C0, 0x25C0}, {0x25FB, 0x25FE}, {0x2600, 0x27BF},
+	{0x2934, 0x2935}, {0x2B05, 0x2B07}, {0x2B1B, 0x2B1C}, {0x2B50, 0x2B50},
+	{0x2B55, 0x2B55}, {0x3030, 0x3030}, {0x303D, 0x303D}, {0x3297, 0x3297},
+	{0x3299, 0x3299}, {0x1F000, 0x1F0FF}, {0x1F10D, 0x1F10F}, {0x1F12F, 0x1F12F},
+	{0x1F16C, 0x1F171}, {0x1F17E, 0x1F17F}, {0x1F18E, 0x1F18E}, {0x1F191, 0x1F19A},
+	{0x1F1AD, 0x1F1E5}, {0x1F201, 0x1F20F}, {0x1F21A, 0x1F21A}, {0x1F22F, 0x1F22F},
+	{0x1F232, 0x1F23A}, {0x1F23C, 0x1F23F}, {0x1F249, 0x1F3FA}, {0x1F400, 0x1F53D},
+	{0x1F546, 0x1F64F}, {0x1F680, 0x1F6FF}, {0x1F774, 0x1F77F}, {0x1F7D5, 0x1F7FF},
+	{0x1F80C, 0x1F80F}, {0x1F848, 0x1F84F}, {0x1F85A, 0x1F85F}, {0x1F888, 0x1F88F},
+	{0x1F8AE, 0x1F8FF}, {0x1F90C, 0x1F93A}, {0x1F93C, 0x1F945}, {0x1F947, 0x1FAFF},
+	{0x1FC00, 0x1FFFD},
+}
+
+func inRanges(r rune, ranges [][2]rune) bool {
+	for i := range ranges {
+		if r >= ranges[i][0] && r <= ranges[i][1] {
+			return true
+		}
+	}
+	return false
+}
+
+func graphemeClassOf(r rune) graphemeClass {
+	switch {
+	case r == '\r':
+		return gcCR
+	case r == '\n':
+		return gcLF
+	case r == 0x200D:
+		return gcZWJ
+	case r == 0x200C,
+		r >= 0xFF9E && r <= 0xFF9F,
+		r >= 0x1F3FB && r <= 0x1F3FF, // emoji modifiers (skin tones)
+		r >= 0xE0020 && r <= 0xE007F, // tags
+		unicode.In(r, unicode.Mn, unicode.Me):
+		return gcExtend
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return gcRegionalIndicator
+	case unicode.In(r, unicode.Cc, unicode.Zl, unicode.Zp, unicode.Cf):
+		return gcControl
+	case unicode.Is(unicode.Mc, r):
+		return gcSpacingMark
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97C:
+		return gcL
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return gcV
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return gcT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return gcLV
+		}
+		return gcLVT
+	case inRanges(r, extendedPictographic):
+		return gcExtendedPictographic
+	}
+	return gcOther
+}
+
+func graphemeBreak(prev, cur graphemeClass, riCount int, afterEmojiZWJ bool) bool {
+	switch {
+	case prev == gcCR && cur == gcLF:
+		return false
+	case prev == gcCR, prev == gcLF, prev == gcControl,
+		cur == gcCR, cur == gcLF, cur == gcControl:
+		return true
+	case prev == gcL && (cur == gcL || cur == gcV || cur == gcLV || cur == gcLVT),
+		(prev == gcLV || prev == gcV) && (cur == gcV || cur == gcT),
+		(prev == gcLVT || prev == gcT) && cur == gcT:
+		return false
+	case cur == gcExtend, cur == gcZWJ, cur == gcSpacingMark:
+		return false
+	case prev == gcZWJ && cur == gcExtendedPictographic && afterEmojiZWJ:
+		return false
+	case prev == gcRegionalIndicator && cur == gcRegionalIndicator && riCount%2 == 1:
+		return false
+	}
+	return true
+}
+
+// splitGraphemes Splits the text into user-perceived characters (extended grapheme clusters),
+// so that emoji with skin tones, flags, ZWJ sequences and combining characters stay intact.
+func splitGraphemes(text string) []string {
+	graphemes := make([]string, 0, len(text))
+	var (
+		start         int
+		prev          graphemeClass
+		riCount       int
+		inEmoji       bool // Extended_Pictographic Extend*
+		afterEmojiZWJ bool // Extended_Pictographic Extend* ZWJ
+	)
+	for i, r := range text {
+		cur := graphemeClassOf(r)
+		broken := i == 0 || graphemeBreak(prev, cur, riCount, afterEmojiZWJ)
+		if broken && i != 0 {
+			graphemes = append(graphemes, text[start:i])
+			start = i
+		}
+
+		if cur == gcRegionalIndicator && !broken && prev == gcRegionalIndicator {
+			riCount++
+		} else if cur == gcRegionalIndicator {
+			riCount = 1
+		} else {
+			riCount = 0
+		}
+
+		switch {
+		case cur == gcExtendedPictographic:
+			inEmoji, afterEmojiZWJ = true, false
+		case cur == gcExtend && inEmoji:
+		case cur == gcZWJ && inEmoji:
+			inEmoji, afterEmojiZWJ = false, true
+		default:
+			inEmoji, afterEmojiZWJ = false, false
+		}
+		prev = cur
+	}
+	if start < len(text) {
+		graphemes = append(graphemes, text[start:])
+	}
+	return graphemes
+}
@@ -0,0 +1,68 @@
+package gwda
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_splitGraphemes(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"abc", []string{"a", "b", "c"}},
+		{"App Store", []string{"A", "p", "p", " ", "S", "t", "o", "r", "e"}},
+		{"中文", []string{"中", "文"}},
+		// combining acute accent
+		{"e\u0301a", []string{"e\u0301", "a"}},
+		// skin tone
+		{"\U0001F44D\U0001F3FD!", []string{"\U0001F44D\U0001F3FD", "!"}},
+		// flags
+		{"\U0001F1E8\U0001F1F3\U0001F1FA\U0001F1F8\U0001F1EF", []string{"\U0001F1E8\U0001F1F3", "\U0001F1FA\U0001F1F8", "\U0001F1EF"}},
+		// ZWJ family
+		{"\U0001F468\u200D\U0001F469\u200D\U0001F467x", []string{"\U0001F468\u200D\U0001F469\u200D\U0001F467", "x"}},
+		// variation selector and ZWJ
+		{"\U0001F3F3\uFE0F\u200D\U0001F308", []string{"\U0001F3F3\uFE0F\u200D\U0001F308"}},
+		// keycap
+		{"1\uFE0F\u20E3", []string{"1\uFE0F\u20E3"}},
+		{"a\r\nb", []string{"a", "\r\n", "b"}},
+		// hangul jamo
+		{"\u1100\u1161\u11A8\uD55C", []string{"\u1100\u1161\u11A8", "\uD55C"}},
+		// ZWJ without emoji
+		{"a\u200D\U0001F44D", []string{"a\u200D", "\U0001F44D"}},
+		// private use
+		{string(KeyboardKeyCommand) + "a", []string{string(KeyboardKeyCommand), "a"}},
+	}
+	for _, tt := range tests {
+		if got := splitGraphemes(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitGraphemes(%+q) = %+q, want %+q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestW3CActions_KeyChord(t *testing.T) {
+	seq, err := NewW3CActions().SendKeysWithModifiers("a\U0001F44D\U0001F3FD", KeyboardKeyShift, KeyboardKeyCommand).Sequence()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = seq.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	var values []string
+	for _, action := range seq[0].Actions {
+		values = append(values, action.Type+":"+action.Value)
+	}
+	want := []string{
+		"keyDown:" + string(KeyboardKeyShift), "keyDown:" + string(KeyboardKeyCommand),
+		"keyDown:a", "keyUp:a", "keyDown:\U0001F44D\U0001F3FD", "keyUp:\U0001F44D\U0001F3FD",
+		"keyUp:" + string(KeyboardKeyCommand), "keyUp:" + string(KeyboardKeyShift),
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Fatalf("got %+q, want %+q", values, want)
+	}
+
+	if err = NewW3CActions().KeyChord(KeyboardKeyCommand, "a").Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"image"
 	"io"
 	"io/ioutil"
 	"log"
@@ -16,6 +17,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -108,6 +110,28 @@ func convertToHTTPClient(_conn net.Conn) *http.Client {
 	}
 }
 
+// convertToRedialHTTPClient works like convertToHTTPClient,
+// but dials a new connection for each request after the first one,
+// so that the MJPEG stream can be requested more than once.
+func convertToRedialHTTPClient(_conn net.Conn, dial func() (net.Conn, error)) *http.Client {
+	var mu sync.Mutex
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if _conn != nil {
+					conn := _conn
+					_conn = nil
+					return conn, nil
+				}
+				return dial()
+			},
+		},
+		Timeout: 0,
+	}
+}
+
 type rawResponse []byte
 
 func (r rawResponse) checkErr() (err error) {
@@ -481,11 +505,57 @@ const (
 	TextDelete    string = "\u007F"
 )
 
-// type KeyboardKeyLabel string
-//
-// const (
-// 	KeyboardKeyReturn = "return"
-// )
+// KeyboardKey A special key of a hardware keyboard, as the code points defined by W3C WebDriver.
+// https://www.w3.org/TR/webdriver/#keyboard-actions
+type KeyboardKey string
+
+const (
+	KeyboardKeyCancel     KeyboardKey = "\uE001"
+	KeyboardKeyHelp       KeyboardKey = "\uE002"
+	KeyboardKeyBackspace  KeyboardKey = "\uE003"
+	KeyboardKeyTab        KeyboardKey = "\uE004"
+	KeyboardKeyClear      KeyboardKey = "\uE005"
+	KeyboardKeyReturn     KeyboardKey = "\uE006"
+	KeyboardKeyEnter      KeyboardKey = "\uE007"
+	KeyboardKeyShift      KeyboardKey = "\uE008"
+	KeyboardKeyControl    KeyboardKey = "\uE009"
+	KeyboardKeyOption     KeyboardKey = "\uE00A" // Alt
+	KeyboardKeyPause      KeyboardKey = "\uE00B"
+	KeyboardKeyEscape     KeyboardKey = "\uE00C"
+	KeyboardKeySpace      KeyboardKey = "\uE00D"
+	KeyboardKeyPageUp     KeyboardKey = "\uE00E"
+	KeyboardKeyPageDown   KeyboardKey = "\uE00F"
+	KeyboardKeyEnd        KeyboardKey = "\uE010"
+	KeyboardKeyHome       KeyboardKey = "\uE011"
+	KeyboardKeyArrowLeft  KeyboardKey = "\uE012"
+	KeyboardKeyArrowUp    KeyboardKey = "\uE013"
+	KeyboardKeyArrowRight KeyboardKey = "\uE014"
+	KeyboardKeyArrowDown  KeyboardKey = "\uE015"
+	KeyboardKeyInsert     KeyboardKey = "\uE016"
+	KeyboardKeyDelete     KeyboardKey = "\uE017"
+	KeyboardKeyF1         KeyboardKey = "\uE031"
+	KeyboardKeyF2         KeyboardKey = "\uE032"
+	KeyboardKeyF3         KeyboardKey = "\uE033"
+	KeyboardKeyF4         KeyboardKey = "\uE034"
+	KeyboardKeyF5         KeyboardKey = "\uE035"
+	KeyboardKeyF6         KeyboardKey = "\uE036"
+	KeyboardKeyF7         KeyboardKey = "\uE037"
+	KeyboardKeyF8         KeyboardKey = "\uE038"
+	KeyboardKeyF9         KeyboardKey = "\uE039"
+	KeyboardKeyF10        KeyboardKey = "\uE03A"
+	KeyboardKeyF11        KeyboardKey = "\uE03B"
+	KeyboardKeyF12        KeyboardKey = "\uE03C"
+	KeyboardKeyCommand    KeyboardKey = "\uE03D" // Meta
+)
+
+// IsModifier Whether the key modifies other keys while it is held
+func (k KeyboardKey) IsModifier() bool {
+	switch k {
+	case KeyboardKeyShift, KeyboardKeyControl, KeyboardKeyOption, KeyboardKeyCommand:
+		return true
+	}
+	return false
+}
 
 // DeviceButton A physical button on an iOS device.
 type DeviceButton string
@@ -994,6 +1064,14 @@ type WebDriver interface {
 	ForceTouch(x, y int, pressure float64, second ...float64) error
 	ForceTouchFloat(x, y, pressure float64, second ...float64) error
 
+	// ScrollUntil Alternates swipes within the container with lookups of the element,
+	// which suits lazy-loaded lists where `WebElement.ScrollElementByPredicate` fails.
+	// The element is returned once it is displayed within the visible rect of the container.
+	//  container: The scrollable element, `nil` for the whole window
+	//  direction: The direction the content is scrolled to, like `WebElement.ScrollDirection`
+	//  maxSwipes: The maximum number of swipes in the given direction
+	ScrollUntil(container WebElement, by BySelector, direction Direction, maxSwipes int, opts ...ScrollUntilOption) (WebElement, error)
+
 	// PerformW3CActions Perform complex touch action in scope of the current application.
 	PerformW3CActions(actions *W3CActions) error
 	PerformAppiumTouchActions(touchActs *TouchActions) error
@@ -1046,6 +1124,8 @@ type WebDriver interface {
 	FindElements(by BySelector) ([]WebElement, error)
 
 	Screenshot() (*bytes.Buffer, error)
+	// ScreenshotImage Returns the decoded screenshot along with the size and scale of the screen
+	ScreenshotImage() (DecodedScreenshot, error)
 
 	// Source Return application elements tree
 	Source(srcOpt ...SourceOption) (string, error)
@@ -1072,6 +1152,11 @@ type WebDriver interface {
 
 	GetMjpegHTTPClient() *http.Client
 
+	// StartRecording Records the screen from the MJPEG stream into the path, see NewScreenRecorder
+	StartRecording(path string, opts ...RecordingOption) error
+	// StopRecording Stops the recording started by StartRecording
+	StopRecording() (RecordingInfo, error)
+
 	// Close inner connections properly
 	Close() error
 }
@@ -1084,6 +1169,9 @@ type WebElement interface {
 	// if element has no keyboard focus.
 	//  frequency: Frequency of typing (letters per sec). The default value is 60
 	SendKeys(text string, frequency ...int) error
+	// TypeText works like SendKeys, but pastes long or complex texts via the pasteboard
+	// and optionally verifies the result with `Text`.
+	TypeText(text string, opts ...TypeTextOption) error
 	// Clear Clears text on element. It will try to activate keyboard on element,
 	// if element has no keyboard focus.
 	Clear() error
@@ -1150,6 +1238,9 @@ type WebElement interface {
 	FindElement(by BySelector) (element WebElement, err error)
 	FindElements(by BySelector) (elements []WebElement, err error)
 	FindVisibleCells() (elements []WebElement, err error)
+	// IterateCells Scrolls a table or collection view in the given direction
+	// and yields each newly visible cell exactly once.
+	IterateCells(direction Direction, opts ...CellIteratorOption) *CellIterator
 
 	Rect() (rect Rect, err error)
 	Location() (Point, error)
@@ -1164,5 +1255,8 @@ type WebElement interface {
 	GetAttribute(attr ElementAttribute) (value string, err error)
 	UID() (uid string)
 
+	// Screenshot Falls back to cropping a screenshot of the screen if WDA fails to take the screenshot of the element
 	Screenshot() (raw *bytes.Buffer, err error)
+	// ScreenshotImage Returns the decoded screenshot of the element
+	ScreenshotImage(opts ...ElementScreenshotOption) (image.Image, error)
 }
@@ -0,0 +1,204 @@
+package gwda
+
+import (
+	"math"
+	"math/rand"
+)
+
+// HumanizerOption Configure the behavior of Humanizer
+type HumanizerOption func(h *Humanizer)
+
+// WithHumanizerJitter The maximum random deviation in points applied to each point of a path.
+//  Defaults to `1.5`
+func WithHumanizerJitter(points float64) HumanizerOption {
+	return func(h *Humanizer) {
+		h.jitter = points
+	}
+}
+
+// WithHumanizerCurvature The maximum bend of a swipe, as a ratio of its length.
+//  Defaults to `0.15`
+func WithHumanizerCurvature(ratio float64) HumanizerOption {
+	return func(h *Humanizer) {
+		h.curvature = ratio
+	}
+}
+
+// WithHumanizerSteps The number of moves a swipe is divided into.
+//  Defaults to `20`
+func WithHumanizerSteps(n int) HumanizerOption {
+	return func(h *Humanizer) {
+		if n > 0 {
+			h.steps = n
+		}
+	}
+}
+
+// WithHumanizerPressPause The range in seconds the finger rests after touching down.
+//  Defaults to `[0.04, 0.12]`
+func WithHumanizerPressPause(min, max float64) HumanizerOption {
+	return func(h *Humanizer) {
+		h.pressPause = [2]float64{min, max}
+	}
+}
+
+// WithHumanizerReleasePause The range in seconds the finger rests before lifting.
+//  Defaults to `[0.02, 0.08]`
+func WithHumanizerReleasePause(min, max float64) HumanizerOption {
+	return func(h *Humanizer) {
+		h.releasePause = [2]float64{min, max}
+	}
+}
+
+// Humanizer Turns a start/end gesture into a natural pointer path:
+// a curved bezier path with eased speed, micro-jitter and realistic pauses after down and before up.
+// The same seed always produces the same paths.
+type Humanizer struct {
+	rnd *rand.Rand
+
+	jitter       float64
+	curvature    float64
+	steps        int
+	pressPause   [2]float64
+	releasePause [2]float64
+}
+
+func NewHumanizer(seed int64, opts ...HumanizerOption) *Humanizer {
+	h := &Humanizer{
+		rnd:          rand.New(rand.NewSource(seed)),
+		jitter:       1.5,
+		curvature:    0.15,
+		steps:        20,
+		pressPause:   [2]float64{0.04, 0.12},
+		releasePause: [2]float64{0.02, 0.08},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// HumanizedPath A pointer path generated by Humanizer.
+//  PressPause: seconds to rest after touching down at the first point
+//  ReleasePause: seconds to rest at the last point before lifting
+type HumanizedPath struct {
+	Points       []PathPoint
+	PressPause   float64
+	ReleasePause float64
+}
+
+func (h *Humanizer) between(r [2]float64) float64 {
+	if r[1] <= r[0] {
+		return r[0]
+	}
+	return r[0] + h.rnd.Float64()*(r[1]-r[0])
+}
+
+func (h *Humanizer) shake(v float64) float64 {
+	if h.jitter <= 0 {
+		return v
+	}
+	return v + (h.rnd.Float64()*2-1)*h.jitter
+}
+
+// easeInOut accelerates at the start and decelerates at the end of a swipe
+func easeInOut(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	return 1 - math.Pow(-2*t+2, 3)/2
+}
+
+// Swipe Generates a path from one coordinate to another.
+//  second: The approximate duration of the movement, varied by up to 10%
+func (h *Humanizer) Swipe(fromX, fromY, toX, toY, second float64) HumanizedPath {
+	second *= 0.9 + h.rnd.Float64()*0.2
+
+	// both control points bend to the same side, by a random amount
+	dx, dy := toX-fromX, toY-fromY
+	normalX, normalY := -dy, dx
+	bend := (h.rnd.Float64()*2 - 1) * h.curvature
+	ctrl1X := fromX + dx/3 + normalX*bend*(0.6+h.rnd.Float64()*0.4)
+	ctrl1Y := fromY + dy/3 + normalY*bend*(0.6+h.rnd.Float64()*0.4)
+	ctrl2X := fromX + dx*2/3 + normalX*bend*(0.6+h.rnd.Float64()*0.4)
+	ctrl2Y := fromY + dy*2/3 + normalY*bend*(0.6+h.rnd.Float64()*0.4)
+
+	points := make([]PathPoint, 0, h.steps+1)
+	for i := 0; i <= h.steps; i++ {
+		t := easeInOut(float64(i) / float64(h.steps))
+		x := math.Pow(1-t, 3)*fromX + 3*math.Pow(1-t, 2)*t*ctrl1X + 3*(1-t)*t*t*ctrl2X + math.Pow(t, 3)*toX
+		y := math.Pow(1-t, 3)*fromY + 3*math.Pow(1-t, 2)*t*ctrl1Y + 3*(1-t)*t*t*ctrl2Y + math.Pow(t, 3)*toY
+		point := PathPoint{X: h.shake(x), Y: h.shake(y)}
+		if i != 0 {
+			point.Duration = second / float64(h.steps)
+		}
+		points = append(points, point)
+	}
+	return HumanizedPath{
+		Points:       points,
+		PressPause:   h.between(h.pressPause),
+		ReleasePause: h.between(h.releasePause),
+	}
+}
+
+// Tap Generates a slightly shaky touch at the coordinate.
+func (h *Humanizer) Tap(x, y float64) HumanizedPath {
+	return HumanizedPath{
+		Points:       []PathPoint{{X: h.shake(x), Y: h.shake(y)}},
+		PressPause:   h.between([2]float64{0.06, 0.14}),
+		ReleasePause: 0,
+	}
+}
+
+// FingerAction Converts the path into a W3C finger action
+func (p HumanizedPath) FingerAction(element ...WebElement) *FingerAction {
+	fingerAction := NewFingerAction(len(p.Points) + 4)
+	for i := range p.Points {
+		fingerAction.Move(newFingerMoveFloat(p.Points[i].X, p.Points[i].Y, p.Points[i].Duration, element...))
+		if i == 0 {
+			fingerAction.Down().Pause(p.PressPause)
+		}
+	}
+	if p.ReleasePause > 0 {
+		fingerAction.Pause(p.ReleasePause)
+	}
+	return fingerAction.Up()
+}
+
+// HumanizedSwipe Swipes along a path generated by the Humanizer
+func (act *W3CActions) HumanizedSwipe(h *Humanizer, fromX, fromY, toX, toY, second float64, element ...WebElement) *W3CActions {
+	return act.FingerAction(h.Swipe(fromX, fromY, toX, toY, second).FingerAction(element...))
+}
+
+// HumanizedTap Taps at a coordinate varied by the Humanizer
+func (act *W3CActions) HumanizedTap(h *Humanizer, x, y float64, element ...WebElement) *W3CActions {
+	return act.FingerAction(h.Tap(x, y).FingerAction(element...))
+}
+
+// TouchActions Appends the path as Appium touch actions
+func (p HumanizedPath) TouchActions(act *TouchActions) *TouchActions {
+	for i := range p.Points {
+		if i == 0 {
+			act.Press(NewTouchActionPress().WithXYFloat(p.Points[i].X, p.Points[i].Y)).Wait(p.PressPause)
+			continue
+		}
+		if p.Points[i].Duration > 0 {
+			act.Wait(p.Points[i].Duration)
+		}
+		act.MoveTo(NewTouchActionMoveTo().WithXYFloat(p.Points[i].X, p.Points[i].Y))
+	}
+	if p.ReleasePause > 0 {
+		act.Wait(p.ReleasePause)
+	}
+	return act.Release()
+}
+
+// HumanizedSwipe Swipes along a path generated by the Humanizer
+func (act *TouchActions) HumanizedSwipe(h *Humanizer, fromX, fromY, toX, toY, second float64) *TouchActions {
+	return h.Swipe(fromX, fromY, toX, toY, second).TouchActions(act)
+}
+
+// HumanizedTap Taps at a coordinate varied by the Humanizer
+func (act *TouchActions) HumanizedTap(h *Humanizer, x, y float64) *TouchActions {
+	return h.Tap(x, y).TouchActions(act)
+}
@@ -0,0 +1,56 @@
+package gwda
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestHumanizer_Swipe(t *testing.T) {
+	path := NewHumanizer(42).Swipe(100, 600, 100, 200, 0.5)
+	if !reflect.DeepEqual(path, NewHumanizer(42).Swipe(100, 600, 100, 200, 0.5)) {
+		t.Fatal("expected the same seed to produce the same path")
+	}
+	if reflect.DeepEqual(path, NewHumanizer(7).Swipe(100, 600, 100, 200, 0.5)) {
+		t.Fatal("expected different seeds to produce different paths")
+	}
+
+	first, last := path.Points[0], path.Points[len(path.Points)-1]
+	if math.Hypot(first.X-100, first.Y-600) > 3 || math.Hypot(last.X-100, last.Y-200) > 3 {
+		t.Fatalf("unexpected endpoints %v %v", first, last)
+	}
+	var total float64
+	for _, p := range path.Points {
+		total += p.Duration
+	}
+	if total < 0.45 || total > 0.55 {
+		t.Fatalf("unexpected duration %v", total)
+	}
+	if path.PressPause <= 0 || path.ReleasePause <= 0 {
+		t.Fatalf("expected pauses, got %v %v", path.PressPause, path.ReleasePause)
+	}
+
+	// eased: the first segment is shorter than the middle one
+	mid := len(path.Points) / 2
+	start := math.Hypot(path.Points[1].X-first.X, path.Points[1].Y-first.Y)
+	middle := math.Hypot(path.Points[mid+1].X-path.Points[mid].X, path.Points[mid+1].Y-path.Points[mid].Y)
+	if start >= middle {
+		t.Fatalf("expected the swipe to accelerate, %v >= %v", start, middle)
+	}
+}
+
+func TestHumanizedPath_TouchActions(t *testing.T) {
+	act := NewTouchActions().HumanizedSwipe(NewHumanizer(1, WithHumanizerSteps(4)), 0, 0, 100, 100, 0.2)
+	if (*act)[0]["action"] != "press" || (*act)[len(*act)-1]["action"] != "release" {
+		t.Fatalf("unexpected actions %v", *act)
+	}
+	moves := 0
+	for _, a := range *act {
+		if a["action"] == "moveTo" {
+			moves++
+		}
+	}
+	if moves != 4 {
+		t.Fatalf("expected 4 moves, got %d", moves)
+	}
+}
@@ -0,0 +1,241 @@
+// Package imgdiff compares screenshots with baseline images for visual regression tests.
+package imgdiff
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+)
+
+// maxYIQDelta The YIQ distance between black and white
+const maxYIQDelta = 35215.0
+
+// Option Configure the behavior of Compare
+type Option func(o *options)
+
+type options struct {
+	tolerance     float64
+	maxDiffRatio  float64
+	minSSIM       float64
+	ignoreRegions []image.Rectangle
+}
+
+// WithTolerance The perceived color distance two pixels may differ by, from 0 (exact) to 1 (black equals white).
+//  Defaults to `0.1`
+func WithTolerance(tolerance float64) Option {
+	return func(o *options) {
+		o.tolerance = tolerance
+	}
+}
+
+// WithMaxDiffRatio The ratio of pixels that may differ for the images to match.
+//  Defaults to `0`
+func WithMaxDiffRatio(ratio float64) Option {
+	return func(o *options) {
+		o.maxDiffRatio = ratio
+	}
+}
+
+// WithMinSSIM The lowest structural similarity for the images to match, from 0 to 1.
+//  Defaults to `0`, which does not check the structural similarity
+func WithMinSSIM(ssim float64) Option {
+	return func(o *options) {
+		o.minSSIM = ssim
+	}
+}
+
+// WithIgnoreRegions Excludes regions, such as the status bar or a clock, from the comparison
+func WithIgnoreRegions(regions ...image.Rectangle) Option {
+	return func(o *options) {
+		o.ignoreRegions = append(o.ignoreRegions, regions...)
+	}
+}
+
+// Result The result of a comparison.
+//  DiffRatio: DiffPixels / ComparedPixels
+//  SSIM: the mean structural similarity of the luminance, from 0 to 1
+//  DiffBounds: the smallest rectangle containing all differing pixels
+//  Diff: the baseline faded to gray, with differing pixels in red and ignored regions in blue
+type Result struct {
+	Match          bool
+	DiffPixels     int
+	ComparedPixels int
+	DiffRatio      float64
+	SSIM           float64
+	DiffBounds     image.Rectangle
+	Diff           *image.RGBA
+}
+
+// ErrSizeMismatch The images are of different sizes
+var ErrSizeMismatch = errors.New("imgdiff: image sizes differ")
+
+// Compare Compares the actual image with the baseline pixel by pixel
+func Compare(baseline, actual image.Image, opts ...Option) (result Result, err error) {
+	o := &options{tolerance: 0.1}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	baseline, actual = toRGBA(baseline), toRGBA(actual)
+	bb, ab := baseline.Bounds(), actual.Bounds()
+	if bb.Dx() != ab.Dx() || bb.Dy() != ab.Dy() {
+		return Result{}, fmt.Errorf("%w: %dx%d and %dx%d", ErrSizeMismatch, bb.Dx(), bb.Dy(), ab.Dx(), ab.Dy())
+	}
+	width, height := bb.Dx(), bb.Dy()
+
+	threshold := maxYIQDelta * o.tolerance * o.tolerance
+	result.Diff = image.NewRGBA(image.Rect(0, 0, width, height))
+	lumaBaseline := make([]float64, width*height)
+	lumaActual := make([]float64, width*height)
+	ignored := make([]bool, width*height)
+	for _, region := range o.ignoreRegions {
+		region = region.Sub(bb.Min).Intersect(image.Rect(0, 0, width, height))
+		for y := region.Min.Y; y < region.Max.Y; y++ {
+			for x := region.Min.X; x < region.Max.X; x++ {
+				ignored[y*width+x] = true
+			}
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := y*width + x
+			c1 := baseline.At(bb.Min.X+x, bb.Min.Y+y)
+			c2 := actual.At(ab.Min.X+x, ab.Min.Y+y)
+			y1, i1, q1 := yiq(c1)
+			lumaBaseline[i] = y1
+			if ignored[i] {
+				lumaActual[i] = y1
+				result.Diff.Set(x, y, color.RGBA{R: 160, G: 200, B: 255, A: 255})
+				continue
+			}
+			y2, i2, q2 := yiq(c2)
+			lumaActual[i] = y2
+			result.ComparedPixels++
+
+			dy, di, dq := y1-y2, i1-i2, q1-q2
+			if 0.5053*dy*dy+0.299*di*di+0.1957*dq*dq > threshold {
+				result.DiffPixels++
+				result.DiffBounds = result.DiffBounds.Union(image.Rect(x, y, x+1, y+1))
+				result.Diff.Set(x, y, color.RGBA{R: 255, A: 255})
+				continue
+			}
+			// faded luminance of the baseline
+			gray := uint8(255 - (255-math.Min(y1, 255))*0.1)
+			result.Diff.Set(x, y, color.RGBA{R: gray, G: gray, B: gray, A: 255})
+		}
+	}
+
+	if result.ComparedPixels != 0 {
+		result.DiffRatio = float64(result.DiffPixels) / float64(result.ComparedPixels)
+	}
+	result.SSIM = ssim(lumaBaseline, lumaActual, width, height)
+	result.Match = result.DiffRatio <= o.maxDiffRatio && (o.minSSIM <= 0 || result.SSIM >= o.minSSIM)
+	return result, nil
+}
+
+// yiq Converts the color into the YIQ color space, with Y from 0 to 255
+func yiq(c color.Color) (y, i, q float64) {
+	r16, g16, b16, a16 := c.RGBA()
+	// blend with white
+	r := 255 + (float64(r16>>8)-255*float64(a16)/0xffff)
+	g := 255 + (float64(g16>>8)-255*float64(a16)/0xffff)
+	b := 255 + (float64(b16>>8)-255*float64(a16)/0xffff)
+	y = r*0.29889531 + g*0.58662247 + b*0.11448223
+	i = r*0.59597799 - g*0.27417610 - b*0.32180189
+	q = r*0.21147017 - g*0.52261711 + b*0.31114694
+	return
+}
+
+// ssim The mean structural similarity over windows of 8x8 pixels
+func ssim(a, b []float64, width, height int) float64 {
+	const (
+		window = 8
+		c1     = (0.01 * 255) * (0.01 * 255)
+		c2     = (0.03 * 255) * (0.03 * 255)
+	)
+	total, windows := 0.0, 0
+	for y0 := 0; y0 < height; y0 += window {
+		for x0 := 0; x0 < width; x0 += window {
+			var sumA, sumB, sumAA, sumBB, sumAB, n float64
+			for y := y0; y < y0+window && y < height; y++ {
+				for x := x0; x < x0+window && x < width; x++ {
+					va, vb := a[y*width+x], b[y*width+x]
+					sumA += va
+					sumB += vb
+					sumAA += va * va
+					sumBB += vb * vb
+					sumAB += va * vb
+					n++
+				}
+			}
+			meanA, meanB := sumA/n, sumB/n
+			varA, varB := sumAA/n-meanA*meanA, sumBB/n-meanB*meanB
+			covar := sumAB/n - meanA*meanB
+			total += ((2*meanA*meanB + c1) * (2*covar + c2)) / ((meanA*meanA + meanB*meanB + c1) * (varA + varB + c2))
+			windows++
+		}
+	}
+	if windows == 0 {
+		return 1
+	}
+	return total / float64(windows)
+}
+
+// CompareWithBaseline Compares the actual image with the baseline PNG.
+// If the images do not match and diffPath is not empty, the diff image is written to diffPath.
+func CompareWithBaseline(baselinePath string, actual image.Image, diffPath string, opts ...Option) (result Result, err error) {
+	var baseline image.Image
+	if baseline, err = LoadPNG(baselinePath); err != nil {
+		return Result{}, err
+	}
+	if result, err = Compare(baseline, actual, opts...); err != nil {
+		return Result{}, err
+	}
+	if !result.Match && diffPath != "" {
+		if err = SavePNG(diffPath, result.Diff); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func LoadPNG(name string) (img image.Image, err error) {
+	var file *os.File
+	if file, err = os.Open(name); err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	if img, err = png.Decode(file); err != nil {
+		return nil, fmt.Errorf("imgdiff: %s: %w", name, err)
+	}
+	return img, nil
+}
+
+// SavePNG Writes the image as PNG, e.g. to create or update a baseline
+func SavePNG(name string, img image.Image) (err error) {
+	var file *os.File
+	if file, err = os.Create(name); err != nil {
+		return err
+	}
+	if err = png.Encode(file, img); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// toRGBA Copies the image, so that repeated pixel access is fast
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok {
+		return rgba
+	}
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba
+}
@@ -0,0 +1,79 @@
+package imgdiff
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newImage(c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func TestCompare(t *testing.T) {
+	baseline := newImage(color.White)
+	actual := newImage(color.White)
+	// a clock in the status bar
+	draw.Draw(actual, image.Rect(0, 0, 64, 8), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	// a barely visible difference
+	actual.Set(30, 30, color.RGBA{R: 250, G: 250, B: 250, A: 255})
+
+	result, err := Compare(baseline, actual, WithIgnoreRegions(image.Rect(0, 0, 64, 8)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Match || result.DiffPixels != 0 || result.ComparedPixels != 64*56 || result.SSIM < 0.999 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	actual.Set(40, 50, color.Black)
+	actual.Set(41, 52, color.Black)
+	if result, err = Compare(baseline, actual, WithIgnoreRegions(image.Rect(0, 0, 64, 8))); err != nil {
+		t.Fatal(err)
+	}
+	if result.Match || result.DiffPixels != 2 || result.DiffBounds != image.Rect(40, 50, 42, 53) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if result.Diff.RGBAAt(40, 50) != (color.RGBA{R: 255, A: 255}) {
+		t.Fatalf("expected the difference to be highlighted, got %v", result.Diff.RGBAAt(40, 50))
+	}
+	if result, _ = Compare(baseline, actual, WithIgnoreRegions(image.Rect(0, 0, 64, 8)), WithMaxDiffRatio(0.001)); !result.Match {
+		t.Fatalf("expected a match within the ratio: %v", result.DiffRatio)
+	}
+	if result, _ = Compare(baseline, actual, WithMaxDiffRatio(1), WithMinSSIM(0.99)); result.Match {
+		t.Fatalf("expected a mismatch by SSIM: %v", result.SSIM)
+	}
+
+	if _, err = Compare(baseline, image.NewRGBA(image.Rect(0, 0, 32, 32))); !errors.Is(err, ErrSizeMismatch) {
+		t.Fatalf("expected %v, got %v", ErrSizeMismatch, err)
+	}
+}
+
+func TestCompareWithBaseline(t *testing.T) {
+	dir := t.TempDir()
+	baselinePath, diffPath := filepath.Join(dir, "baseline.png"), filepath.Join(dir, "diff.png")
+	if err := SavePNG(baselinePath, newImage(color.White)); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := CompareWithBaseline(baselinePath, newImage(color.White), diffPath)
+	if err != nil || !result.Match {
+		t.Fatalf("expected a match: %v %+v", err, result)
+	}
+	if _, err = os.Stat(diffPath); !os.IsNotExist(err) {
+		t.Fatal("expected no diff image for a match")
+	}
+
+	if result, err = CompareWithBaseline(baselinePath, newImage(color.Black), diffPath); err != nil || result.Match {
+		t.Fatalf("expected a mismatch: %v %+v", err, result)
+	}
+	if _, err = LoadPNG(diffPath); err != nil {
+		t.Fatal(err)
+	}
+}
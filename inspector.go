@@ -0,0 +1,387 @@
+package gwda
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"image/jpeg"
+	"image/png"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed assets/inspector.html
+var inspectorHTML []byte
+
+// Inspector A local web UI to inspect the application under test.
+// It shows the live screenshot, the elements tree, the attributes and suggested selectors of the selected element,
+// and taps through the driver when the screenshot is clicked in tap mode.
+// The taps, swipes and typed texts are recorded, the generated test is served by `/api/code`.
+//  Routes:
+//  GET    /               the web UI
+//  GET    /api/screenshot the upright screenshot
+//  GET    /api/source     the elements tree, see `inspectorNode`
+//  POST   /api/tap        taps `{"x": 0, "y": 0}` in points
+//  POST   /api/swipe      swipes `{"fromX": 0, "fromY": 0, "toX": 0, "toY": 0}` in points
+//  POST   /api/type       types `{"text": ""}` into the focused element
+//  GET    /api/code       the Go test replaying the recorded actions
+//  DELETE /api/code       discards the recorded actions
+// The routes acting on the device require `Content-Type: application/json` and reject the requests of other origins,
+// and all routes reject a `Host` other than the listen address,
+// so that the web pages opened in the browser can neither drive the device nor read it.
+type Inspector struct {
+	driver   WebDriver
+	mux      *http.ServeMux
+	recorder *ActionRecorder
+	// mu serializes the requests to WDA, the browser polls while taps are in flight
+	mu sync.Mutex
+	// addr The address of ListenAndServe, the only `Host` accepted, empty when mounted on another server
+	addr string
+}
+
+// NewInspector Returns an `http.Handler`, so that it can be mounted on an existing server
+func NewInspector(driver WebDriver) *Inspector {
+	in := &Inspector{driver: driver, mux: http.NewServeMux(), recorder: NewActionRecorder()}
+	in.mux.HandleFunc("/", in.handleIndex)
+	in.mux.HandleFunc("/api/screenshot", in.handleScreenshot)
+	in.mux.HandleFunc("/api/source", in.handleSource)
+	in.mux.HandleFunc("/api/tap", in.handleTap)
+	in.mux.HandleFunc("/api/swipe", in.handleSwipe)
+	in.mux.HandleFunc("/api/type", in.handleType)
+	in.mux.HandleFunc("/api/code", in.handleCode)
+	return in
+}
+
+// Recorder Returns the recorder of the actions made through the web UI
+func (in *Inspector) Recorder() *ActionRecorder {
+	return in.recorder
+}
+
+// ListenAndServe Serves the inspector on the address, e.g. `127.0.0.1:8100`
+func (in *Inspector) ListenAndServe(addr string) error {
+	in.addr = addr
+	return http.ListenAndServe(addr, in)
+}
+
+// ServeHTTP Rejects the requests for a `Host` other than the listen address on every route,
+// so that a DNS rebinding page can neither drive the device nor read its screen
+func (in *Inspector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !in.allowedHost(r.Host) {
+		inspectorError(w, http.StatusForbidden, fmt.Errorf("host not allowed: %s", r.Host))
+		return
+	}
+	in.mux.ServeHTTP(w, r)
+}
+
+func (in *Inspector) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(inspectorHTML)
+}
+
+func (in *Inspector) handleScreenshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		inspectorError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	in.mu.Lock()
+	screenshot, err := in.driver.ScreenshotImage()
+	in.mu.Unlock()
+	if err != nil {
+		inspectorError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Point-Width", strconv.Itoa(screenshot.PointSize.Width))
+	w.Header().Set("X-Point-Height", strconv.Itoa(screenshot.PointSize.Height))
+	if screenshot.Format == "jpeg" {
+		w.Header().Set("Content-Type", "image/jpeg")
+		err = jpeg.Encode(w, screenshot.Image, &jpeg.Options{Quality: 90})
+	} else {
+		w.Header().Set("Content-Type", "image/png")
+		err = png.Encode(w, screenshot.Image)
+	}
+	if err != nil {
+		debugLog(fmt.Sprintf("inspector: encode screenshot: %v", err))
+	}
+}
+
+// inspectorNode The json of a `SourceNode` for the web UI, without the cyclic parent
+type inspectorNode struct {
+	ID           int                 `json:"id"`
+	Type         string              `json:"type"`
+	Name         string              `json:"name,omitempty"`
+	Label        string              `json:"label,omitempty"`
+	Value        string              `json:"value,omitempty"`
+	Rect         Rect                `json:"rect"`
+	IsEnabled    bool                `json:"isEnabled"`
+	IsVisible    bool                `json:"isVisible"`
+	IsAccessible bool                `json:"isAccessible"`
+	Selectors    []inspectorSelector `json:"selectors"`
+	Children     []*inspectorNode    `json:"children,omitempty"`
+}
+
+type inspectorSelector struct {
+	Using string `json:"using"`
+	Value string `json:"value"`
+}
+
+func (in *Inspector) handleSource(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		inspectorError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+	in.mu.Lock()
+	root, err := SourceTree(in.driver)
+	in.mu.Unlock()
+	if err != nil {
+		inspectorError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	id := 0
+	var convert func(node *SourceNode) *inspectorNode
+	convert = func(node *SourceNode) *inspectorNode {
+		n := &inspectorNode{
+			ID:           id,
+			Type:         node.Type,
+			Name:         node.Name,
+			Label:        node.Label,
+			Value:        node.Value,
+			Rect:         node.Rect,
+			IsEnabled:    node.IsEnabled,
+			IsVisible:    node.IsVisible,
+			IsAccessible: node.IsAccessible,
+			Selectors:    inspectorSelectors(node),
+		}
+		id++
+		for _, child := range node.Children {
+			n.Children = append(n.Children, convert(child))
+		}
+		return n
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(convert(root))
+}
+
+func (in *Inspector) handleTap(w http.ResponseWriter, r *http.Request) {
+	if !in.checkRequest(w, r, http.MethodPost, true) {
+		return
+	}
+	var point struct {
+		X *float64 `json:"x"`
+		Y *float64 `json:"y"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&point); err != nil {
+		inspectorError(w, http.StatusBadRequest, fmt.Errorf("tap: %w", err))
+		return
+	}
+	if point.X == nil || point.Y == nil {
+		inspectorError(w, http.StatusBadRequest, fmt.Errorf("tap: missing x or y"))
+		return
+	}
+	in.mu.Lock()
+	// the tapped element is located in the screen as it is right before the tap,
+	// the tree shown in the web UI may be outdated
+	root, sourceErr := SourceTree(in.driver)
+	err := in.driver.TapFloat(*point.X, *point.Y)
+	if err == nil {
+		if sourceErr == nil {
+			in.recorder.RecordTapAt(root, *point.X, *point.Y)
+		} else {
+			in.recorder.RecordTap(*point.X, *point.Y)
+		}
+	}
+	in.mu.Unlock()
+	if err != nil {
+		inspectorError(w, http.StatusBadGateway, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (in *Inspector) handleSwipe(w http.ResponseWriter, r *http.Request) {
+	if !in.checkRequest(w, r, http.MethodPost, true) {
+		return
+	}
+	var swipe struct {
+		FromX *float64 `json:"fromX"`
+		FromY *float64 `json:"fromY"`
+		ToX   *float64 `json:"toX"`
+		ToY   *float64 `json:"toY"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&swipe); err != nil {
+		inspectorError(w, http.StatusBadRequest, fmt.Errorf("swipe: %w", err))
+		return
+	}
+	if swipe.FromX == nil || swipe.FromY == nil || swipe.ToX == nil || swipe.ToY == nil {
+		inspectorError(w, http.StatusBadRequest, fmt.Errorf("swipe: missing fromX, fromY, toX or toY"))
+		return
+	}
+	in.mu.Lock()
+	err := in.driver.SwipeFloat(*swipe.FromX, *swipe.FromY, *swipe.ToX, *swipe.ToY)
+	if err == nil {
+		in.recorder.RecordSwipe(*swipe.FromX, *swipe.FromY, *swipe.ToX, *swipe.ToY)
+	}
+	in.mu.Unlock()
+	if err != nil {
+		inspectorError(w, http.StatusBadGateway, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (in *Inspector) handleType(w http.ResponseWriter, r *http.Request) {
+	if !in.checkRequest(w, r, http.MethodPost, true) {
+		return
+	}
+	var input struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		inspectorError(w, http.StatusBadRequest, fmt.Errorf("type: %w", err))
+		return
+	}
+	if input.Text == "" {
+		inspectorError(w, http.StatusBadRequest, fmt.Errorf("type: missing text"))
+		return
+	}
+	in.mu.Lock()
+	err := in.driver.SendKeys(input.Text)
+	if err == nil {
+		in.recorder.RecordSendKeys(input.Text)
+	}
+	in.mu.Unlock()
+	if err != nil {
+		inspectorError(w, http.StatusBadGateway, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (in *Inspector) handleCode(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		code, err := in.recorder.TestFile("main_test", "TestRecorded")
+		if err != nil {
+			inspectorError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write(code)
+	case http.MethodDelete:
+		if !in.checkRequest(w, r, http.MethodDelete, false) {
+			return
+		}
+		in.recorder.Reset()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		inspectorError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+	}
+}
+
+// checkRequest Rejects the requests acting on the device from other origins.
+// Browsers send cross-origin `text/plain` posts without a preflight, but not `application/json` ones.
+func (in *Inspector) checkRequest(w http.ResponseWriter, r *http.Request, method string, requireJSON bool) bool {
+	if r.Method != method {
+		inspectorError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return false
+	}
+	if requireJSON {
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			inspectorError(w, http.StatusUnsupportedMediaType, fmt.Errorf("expected Content-Type: application/json"))
+			return false
+		}
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+			inspectorError(w, http.StatusForbidden, fmt.Errorf("origin not allowed: %s", origin))
+			return false
+		}
+	}
+	return true
+}
+
+// allowedHost Accepts the listen address, or `localhost` and the loopback addresses on its port
+// when it listens on a loopback or unspecified address
+func (in *Inspector) allowedHost(host string) bool {
+	if in.addr == "" || host == in.addr {
+		return true
+	}
+	addrHost, addrPort, err := net.SplitHostPort(in.addr)
+	if err != nil {
+		return false
+	}
+	reqHost, reqPort, err := net.SplitHostPort(host)
+	if err != nil || reqPort != addrPort {
+		return false
+	}
+	if ip := net.ParseIP(addrHost); addrHost != "" && addrHost != "localhost" && (ip == nil || !(ip.IsLoopback() || ip.IsUnspecified())) {
+		return reqHost == addrHost
+	}
+	ip := net.ParseIP(reqHost)
+	return reqHost == "localhost" || (ip != nil && ip.IsLoopback())
+}
+
+func inspectorError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
+
+// inspectorSelectors The suggested selectors of the node, see `SourceNode.SuggestSelectors`
+func inspectorSelectors(node *SourceNode) (selectors []inspectorSelector) {
+	for _, by := range node.SuggestSelectors() {
+		using, value := by.getUsingAndValue()
+		selectors = append(selectors, inspectorSelector{Using: using, Value: value})
+	}
+	return
+}
+
+// absoluteXPath e.g. `/XCUIElementTypeApplication/XCUIElementTypeWindow[1]/XCUIElementTypeButton[2]`
+func absoluteXPath(node *SourceNode) string {
+	var steps []string
+	for n := node; n != nil; n = n.Parent {
+		if n.Parent == nil {
+			steps = append(steps, n.Type)
+			break
+		}
+		steps = append(steps, fmt.Sprintf("%s[%d]", n.Type, n.Index()))
+	}
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return "/" + strings.Join(steps, "/")
+}
+
+// predicateLiteral Quotes the string for NSPredicate
+func predicateLiteral(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// xpathLiteral Quotes the string for XPath 1.0, which has no escape sequences
+func xpathLiteral(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	if !strings.Contains(s, `"`) {
+		return `"` + s + `"`
+	}
+	parts := strings.Split(s, "'")
+	for i := range parts {
+		parts[i] = "'" + parts[i] + "'"
+	}
+	return "concat(" + strings.Join(parts, `, "'", `) + ")"
+}
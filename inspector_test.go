@@ -0,0 +1,160 @@
+package gwda
+
+import (
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const inspectorSource = `{"type":"Application","name":"Demo","rect":{"x":0,"y":0,"width":160,"height":120},"isVisible":"1","children":[
+{"type":"Window","rect":{"x":0,"y":0,"width":160,"height":120},"isVisible":"1","children":[
+{"type":"Button","name":"OK","label":"OK","rect":{"x":10,"y":20,"width":40,"height":20},"isVisible":"1","isEnabled":"1"},
+{"type":"Button","label":"Don't \"go\"","rect":{"x":60,"y":20,"width":40,"height":20},"isVisible":"1"}]}]}`
+
+type fakeInspectorDriver struct {
+	fakeImageDriver
+}
+
+func (wd *fakeInspectorDriver) Source(srcOpt ...SourceOption) (string, error) {
+	return inspectorSource, nil
+}
+
+func TestInspector(t *testing.T) {
+	wd := &fakeInspectorDriver{fakeImageDriver{screen: newBlockImage(320, 240, 2)}}
+	server := httptest.NewServer(NewInspector(wd))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Fatalf("unexpected index: %s %s", resp.Status, resp.Header.Get("Content-Type"))
+	}
+
+	if resp, err = http.Get(server.URL + "/api/screenshot"); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 320 || resp.Header.Get("X-Point-Width") != "160" {
+		t.Fatalf("unexpected screenshot: %v %s", img.Bounds(), resp.Header.Get("X-Point-Width"))
+	}
+
+	if resp, err = http.Get(server.URL + "/api/source"); err != nil {
+		t.Fatal(err)
+	}
+	var root inspectorNode
+	err = json.NewDecoder(resp.Body).Decode(&root)
+	_ = resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buttons := root.Children[0].Children
+	if len(buttons) != 2 || buttons[0].ID != 2 || buttons[1].ID != 3 || buttons[0].Rect.X != 10 {
+		t.Fatalf("unexpected tree: %+v", root)
+	}
+	if buttons[0].Selectors[0] != (inspectorSelector{Using: "accessibility id", Value: "OK"}) {
+		t.Fatalf("unexpected selectors: %+v", buttons[0].Selectors)
+	}
+	expected := []inspectorSelector{
+		{Using: "predicate string", Value: `type == "XCUIElementTypeButton" AND label == "Don't \"go\""`},
+		{Using: "class chain", Value: "**/XCUIElementTypeButton[`label == \"Don't \\\"go\\\"\"`]"},
+		{Using: "xpath", Value: `//XCUIElementTypeButton[@label=concat('Don', "'", 't "go"')]`},
+		{Using: "xpath", Value: "/XCUIElementTypeApplication/XCUIElementTypeWindow[1]/XCUIElementTypeButton[2]"},
+	}
+	if len(buttons[1].Selectors) != len(expected) {
+		t.Fatalf("unexpected selectors: %+v", buttons[1].Selectors)
+	}
+	for i := range expected {
+		if buttons[1].Selectors[i] != expected[i] {
+			t.Fatalf("selector %d: expected %+v, got %+v", i, expected[i], buttons[1].Selectors[i])
+		}
+	}
+
+	if resp, err = http.Post(server.URL+"/api/tap", "application/json", strings.NewReader(`{"x":30.5,"y":30}`)); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || wd.tapX != 30.5 || wd.tapY != 30 {
+		t.Fatalf("unexpected tap: %s (%v, %v)", resp.Status, wd.tapX, wd.tapY)
+	}
+	if resp, err = http.Post(server.URL+"/api/tap", "application/json", strings.NewReader(`{"x":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected bad request, got %s", resp.Status)
+	}
+}
+
+func TestInspectorRejectsCrossOrigin(t *testing.T) {
+	wd := &fakeInspectorDriver{fakeImageDriver{screen: newBlockImage(320, 240, 2)}}
+	server := httptest.NewServer(NewInspector(wd))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		contentType, origin string
+		expected            int
+	}{
+		{"text/plain", "", http.StatusUnsupportedMediaType},
+		{"application/json", "http://evil.example", http.StatusForbidden},
+		{"application/json", server.URL, http.StatusNoContent},
+		{"application/json; charset=utf-8", "", http.StatusNoContent},
+	} {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/tap", strings.NewReader(`{"x":1,"y":2}`))
+		req.Header.Set("Content-Type", tt.contentType)
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != tt.expected {
+			t.Errorf("%s %s: expected %d, got %s", tt.contentType, tt.origin, tt.expected, resp.Status)
+		}
+	}
+}
+
+func TestInspectorAllowedHost(t *testing.T) {
+	for addr, hosts := range map[string]map[string]bool{
+		"127.0.0.1:8200": {"127.0.0.1:8200": true, "localhost:8200": true, "[::1]:8200": true, "localhost:9000": false, "evil.example:8200": false},
+		":8200":          {"localhost:8200": true, "192.168.1.2:8200": false, "evil.example:8200": false},
+		"10.0.0.5:8200":  {"10.0.0.5:8200": true, "localhost:8200": false},
+	} {
+		in := &Inspector{addr: addr}
+		for host, expected := range hosts {
+			if got := in.allowedHost(host); got != expected {
+				t.Errorf("%s, host %s: expected %v", addr, host, expected)
+			}
+		}
+	}
+}
+
+func TestInspectorRejectsForeignHost(t *testing.T) {
+	wd := &fakeInspectorDriver{fakeImageDriver{screen: newBlockImage(320, 240, 2)}}
+	in := NewInspector(wd)
+	in.addr = "127.0.0.1:8200"
+	for _, path := range []string{"/", "/api/screenshot", "/api/source", "/api/code"} {
+		// a DNS rebinding page requests its own host name
+		w := httptest.NewRecorder()
+		in.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://evil.example:8200"+path, nil))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected %d, got %d", path, http.StatusForbidden, w.Code)
+		}
+		w = httptest.NewRecorder()
+		in.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://localhost:8200"+path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: expected %d, got %d", path, http.StatusOK, w.Code)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var errKeyboardNotShown = errors.New("keyboard is not shown")
+
+// KeyboardPlane The set of keys currently shown by the on-screen keyboard
+type KeyboardPlane string
+
+const (
+	KeyboardPlaneUnknown KeyboardPlane = ""
+	KeyboardPlaneLetters KeyboardPlane = "letters"
+	KeyboardPlaneNumbers KeyboardPlane = "numbers"
+	KeyboardPlaneSymbols KeyboardPlane = "symbols"
+)
+
+// keyboardPlaneKeys The labels (case-insensitive) of the keys that switch to a plane
+var keyboardPlaneKeys = map[KeyboardPlane][]string{
+	KeyboardPlaneLetters: {"letters", "ABC", "abc"},
+	KeyboardPlaneNumbers: {"numbers", "123", ".?123"},
+	KeyboardPlaneSymbols: {"symbols", "#+="},
+}
+
+// KeyboardKeyInfo A key of the on-screen keyboard
+type KeyboardKeyInfo struct {
+	Name  string
+	Label string
+	Rect  Rect
+}
+
+// KeyboardInfo The state of the on-screen keyboard.
+//  Layout: the first six letters of the top row, e.g. `qwerty`, `azerty` or `qwertz`
+//  SpaceKeyLabel: the space key is labelled with the name of the active input language on most keyboards
+type KeyboardInfo struct {
+	Rect          Rect
+	Plane         KeyboardPlane
+	Layout        string
+	SpaceKeyLabel string
+	Keys          []KeyboardKeyInfo
+}
+
+// Key Returns the key with the label or name, or nil.
+// Labels of single characters are case-sensitive, the others such as `Return` are not.
+func (ki KeyboardInfo) Key(label string) *KeyboardKeyInfo {
+	for i := range ki.Keys {
+		if ki.Keys[i].Label == label {
+			return &ki.Keys[i]
+		}
+	}
+	for i := range ki.Keys {
+		if ki.Keys[i].Name == label {
+			return &ki.Keys[i]
+		}
+	}
+	if utf8.RuneCountInString(label) > 1 {
+		return ki.keyFold(label)
+	}
+	return nil
+}
+
+func (ki KeyboardInfo) keyFold(labels ...string) *KeyboardKeyInfo {
+	for _, label := range labels {
+		for i := range ki.Keys {
+			if strings.EqualFold(ki.Keys[i].Label, label) || strings.EqualFold(ki.Keys[i].Name, label) {
+				return &ki.Keys[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Keyboard Inspects the on-screen keyboard and taps its keys one by one,
+// for custom text fields that ignore `WebDriver.SendKeys`.
+type Keyboard struct {
+	wd WebDriver
+}
+
+func NewKeyboard(driver WebDriver) *Keyboard {
+	return &Keyboard{wd: driver}
+}
+
+// IsShown Whether the on-screen keyboard is visible
+func (kb *Keyboard) IsShown() (bool, error) {
+	elements, err := kb.wd.FindElements(BySelector{ClassName: ElementType{Keyboard: true}})
+	if err != nil {
+		if errors.Is(err, errNoSuchElement) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, elem := range elements {
+		if displayed, err := elem.IsDisplayed(); err != nil {
+			return false, err
+		} else if displayed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Info Returns the rect, keys and current plane of the on-screen keyboard
+func (kb *Keyboard) Info() (info KeyboardInfo, err error) {
+	var root *SourceNode
+	if root, err = SourceTree(kb.wd); err != nil {
+		return KeyboardInfo{}, err
+	}
+	return parseKeyboardInfo(root)
+}
+
+func parseKeyboardInfo(root *SourceNode) (info KeyboardInfo, err error) {
+	var keyboard *SourceNode
+	for _, node := range root.FindByType(ElementType{Keyboard: true}) {
+		if node.IsVisible && node.Rect.Width > 0 && node.Rect.Height > 0 {
+			keyboard = node
+			break
+		}
+	}
+	if keyboard == nil {
+		return KeyboardInfo{}, errKeyboardNotShown
+	}
+
+	info.Rect = keyboard.Rect
+	keyType, buttonType := ElementType{Key: true}.String(), ElementType{Button: true}.String()
+	keyboard.Walk(func(node *SourceNode) bool {
+		if node.Type != keyType && node.Type != buttonType {
+			return true
+		}
+		if node.Rect.Width > 0 && node.Rect.Height > 0 {
+			info.Keys = append(info.Keys, KeyboardKeyInfo{Name: node.Name, Label: node.Label, Rect: node.Rect})
+		}
+		return false
+	})
+
+	var letters []KeyboardKeyInfo
+	hasDigit, hasBracket := false, false
+	for _, key := range info.Keys {
+		switch {
+		case key.Label == "[" || key.Label == "{":
+			hasBracket = true
+		case key.Label == "1":
+			hasDigit = true
+		case isLetterKey(key.Label):
+			letters = append(letters, key)
+		}
+		if strings.EqualFold(key.Name, "space") {
+			info.SpaceKeyLabel = key.Label
+		}
+	}
+	switch {
+	case hasBracket:
+		info.Plane = KeyboardPlaneSymbols
+	case hasDigit:
+		info.Plane = KeyboardPlaneNumbers
+	case len(letters) != 0:
+		info.Plane = KeyboardPlaneLetters
+		info.Layout = keyboardLayout(letters)
+	}
+	return
+}
+
+func isLetterKey(label string) bool {
+	r, size := utf8.DecodeRuneInString(label)
+	return size == len(label) && unicode.IsLetter(r)
+}
+
+// keyboardLayout Reads the first six letters of the top row from left to right
+func keyboardLayout(letters []KeyboardKeyInfo) string {
+	top := letters[0].Rect.Y
+	for _, key := range letters {
+		if key.Rect.Y < top {
+			top = key.Rect.Y
+		}
+	}
+	row := make([]KeyboardKeyInfo, 0, 10)
+	for _, key := range letters {
+		if key.Rect.Y-top < key.Rect.Height/2 {
+			row = append(row, key)
+		}
+	}
+	sort.Slice(row, func(i, j int) bool { return row[i].Rect.X < row[j].Rect.X })
+	var sb strings.Builder
+	for i := 0; i < len(row) && i < 6; i++ {
+		sb.WriteString(strings.ToLower(row[i].Label))
+	}
+	return sb.String()
+}
+
+// SwitchPlane Taps the `more` keys until the keyboard shows the plane
+func (kb *Keyboard) SwitchPlane(plane KeyboardPlane) (err error) {
+	if plane == KeyboardPlaneUnknown {
+		return errors.New("unknown keyboard plane")
+	}
+	var info KeyboardInfo
+	for i := 0; i < 3; i++ {
+		if info, err = kb.Info(); err != nil {
+			return err
+		}
+		if info.Plane == plane {
+			return nil
+		}
+		key := info.keyFold(keyboardPlaneKeys[plane]...)
+		if key == nil && plane == KeyboardPlaneSymbols && info.Plane == KeyboardPlaneLetters {
+			// the symbols are reached through the numbers
+			key = info.keyFold(keyboardPlaneKeys[KeyboardPlaneNumbers]...)
+		}
+		if key == nil {
+			key = info.keyFold("more")
+		}
+		if key == nil {
+			return fmt.Errorf("no key switches the keyboard from '%s' to '%s'", info.Plane, plane)
+		}
+		if err = kb.tap(key); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("keyboard switched to '%s' instead of '%s'", info.Plane, plane)
+}
+
+// TapKey Taps the key with the label or name, switching the plane
+// or pressing `shift` for capital letters if needed.
+func (kb *Keyboard) TapKey(label string) (err error) {
+	var info KeyboardInfo
+	if info, err = kb.Info(); err != nil {
+		return err
+	}
+	if key := info.Key(label); key != nil {
+		return kb.tap(key)
+	}
+
+	if isLetterKey(label) && info.Plane == KeyboardPlaneLetters {
+		if shift := info.keyFold("shift"); shift != nil {
+			if err = kb.tap(shift); err != nil {
+				return err
+			}
+			if info, err = kb.Info(); err != nil {
+				return err
+			}
+			if key := info.Key(label); key != nil {
+				return kb.tap(key)
+			}
+			// shift stays on until a letter is typed
+			if shift = info.keyFold("shift"); shift != nil {
+				if err = kb.tap(shift); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	for _, plane := range []KeyboardPlane{KeyboardPlaneLetters, KeyboardPlaneNumbers, KeyboardPlaneSymbols} {
+		if plane == info.Plane {
+			continue
+		}
+		// a plane that cannot be reached is skipped, a later one may have the key
+		_ = kb.SwitchPlane(plane)
+		if info, err = kb.Info(); err != nil {
+			return err
+		}
+		if key := info.Key(label); key != nil {
+			return kb.tap(key)
+		}
+	}
+	return fmt.Errorf("%w: keyboard key '%s'", errNoSuchElement, label)
+}
+
+// TypeText Types the text by tapping the keys one by one
+func (kb *Keyboard) TypeText(text string) (err error) {
+	for _, grapheme := range splitGraphemes(text) {
+		label := grapheme
+		switch grapheme {
+		case " ":
+			label = "space"
+		case "\n", "\r\n":
+			label = "Return"
+		}
+		if err = kb.TapKey(label); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (kb *Keyboard) tap(key *KeyboardKeyInfo) error {
+	return kb.wd.TapFloat(float64(key.Rect.X)+float64(key.Rect.Width)/2, float64(key.Rect.Y)+float64(key.Rect.Height)/2)
+}
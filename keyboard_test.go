@@ -0,0 +1,176 @@
+package gwda
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// fakeKeyboardDriver Renders an on-screen keyboard of three rows in the source
+// and switches its plane when the `more` key is tapped.
+type fakeKeyboardDriver struct {
+	WebDriver
+	plane KeyboardPlane
+	shift bool
+	typed string
+	// stuck A key doing nothing when tapped
+	stuck string
+}
+
+var fakeKeyboardRows = map[KeyboardPlane][][]string{
+	KeyboardPlaneLetters: {{"q", "w", "e", "r", "t", "y", "u", "i", "o", "p"}, {"a", "s", "d", "f", "g", "h", "j", "k", "l"}, {"shift", "z", "x", "c", "v", "b", "n", "m", "delete"}},
+	KeyboardPlaneNumbers: {{"1", "2", "3", "4", "5", "6", "7", "8", "9", "0"}, {"-", "/", ":", ";", "(", ")", "$", "&", "@", "\""}, {"symbols", ".", ",", "?", "!", "'", "delete"}},
+	KeyboardPlaneSymbols: {{"[", "]", "{", "}", "#", "%", "^", "*", "+", "="}, {"_", "\\", "|", "~", "<", ">"}, {"numbers", ".", ",", "?", "!", "'", "delete"}},
+}
+
+func (wd *fakeKeyboardDriver) keys() (keys []map[string]interface{}) {
+	rows := append(fakeKeyboardRows[wd.plane], []string{"more", "space", "Return"})
+	for y, row := range rows {
+		for x, label := range row {
+			name := label
+			switch {
+			case label == "more" && wd.plane == KeyboardPlaneLetters:
+				label = "numbers"
+			case label == "more":
+				label = "letters"
+			case label == "space":
+				label = "English"
+			case wd.shift && isLetterKey(label):
+				label, name = string(label[0]-'a'+'A'), string(label[0]-'a'+'A')
+			}
+			keys = append(keys, map[string]interface{}{
+				"type": "Key", "name": name, "label": label, "isVisible": "1",
+				"rect": map[string]float64{"x": float64(x * 37), "y": 500 + float64(y*54), "width": 37, "height": 54},
+			})
+		}
+	}
+	return
+}
+
+func (wd *fakeKeyboardDriver) Source(_ ...SourceOption) (string, error) {
+	keys := wd.keys()
+	children := make([]interface{}, len(keys))
+	for i := range keys {
+		children[i] = keys[i]
+	}
+	bsJSON, err := json.Marshal(map[string]interface{}{
+		"type": "Application", "name": "Demo", "isVisible": "1",
+		"rect": map[string]float64{"x": 0, "y": 0, "width": 375, "height": 812},
+		"children": []interface{}{map[string]interface{}{
+			"type": "XCUIElementTypeKeyboard", "isVisible": "1",
+			"rect":     map[string]float64{"x": 0, "y": 500, "width": 375, "height": 216},
+			"children": children,
+		}},
+	})
+	return string(bsJSON), err
+}
+
+func (wd *fakeKeyboardDriver) TapFloat(x, y float64) error {
+	for _, key := range wd.keys() {
+		rect := key["rect"].(map[string]float64)
+		if x < rect["x"] || x >= rect["x"]+rect["width"] || y < rect["y"] || y >= rect["y"]+rect["height"] {
+			continue
+		}
+		switch name := key["name"].(string); name {
+		case wd.stuck:
+		case "more":
+			if wd.plane == KeyboardPlaneLetters {
+				wd.plane = KeyboardPlaneNumbers
+			} else {
+				wd.plane = KeyboardPlaneLetters
+			}
+		case "symbols":
+			wd.plane = KeyboardPlaneSymbols
+		case "numbers":
+			wd.plane = KeyboardPlaneNumbers
+		case "shift":
+			wd.shift = !wd.shift
+		case "space":
+			wd.typed += " "
+		case "Return":
+			wd.typed += "\n"
+		default:
+			wd.typed += name
+			wd.shift = false
+		}
+		return nil
+	}
+	return nil
+}
+
+func TestParseSourceTree(t *testing.T) {
+	root, err := ParseSourceTree(`{"type":"Application","name":"Demo","isEnabled":"1","rect":{"x":0,"y":0,"width":375.5,"height":812},
+		"children":[{"type":"Other","children":[{"type":"Button","name":"OK","value":null,"isVisible":"1","rect":{"x":10,"y":20,"width":30,"height":40}},
+		{"type":"Button","name":"Cancel","value":3,"isVisible":"0","rect":{"x":50,"y":20,"width":30,"height":40}}]}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Type != "XCUIElementTypeApplication" || !root.IsEnabled || root.Rect.Width != 376 {
+		t.Fatalf("unexpected root: %s", root)
+	}
+	buttons := root.FindByType(ElementType{Button: true})
+	if len(buttons) != 2 {
+		t.Fatalf("expected 2 buttons, got %d", len(buttons))
+	}
+	if !buttons[0].IsVisible || buttons[1].IsVisible || buttons[1].Value != "3" || buttons[1].Index() != 2 {
+		t.Fatalf("unexpected buttons: %s, %s", buttons[0], buttons[1])
+	}
+	if buttons[0].Parent.Parent != root {
+		t.Fatal("parent is not linked")
+	}
+	if x, y := buttons[0].Center(); x != 25 || y != 40 {
+		t.Fatalf("unexpected center: (%v, %v)", x, y)
+	}
+}
+
+func TestKeyboard_Info(t *testing.T) {
+	kb := NewKeyboard(&fakeKeyboardDriver{plane: KeyboardPlaneLetters})
+	info, err := kb.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Plane != KeyboardPlaneLetters || info.Layout != "qwerty" || info.SpaceKeyLabel != "English" {
+		t.Fatalf("unexpected keyboard: %s %s %s", info.Plane, info.Layout, info.SpaceKeyLabel)
+	}
+	if info.Rect.Y != 500 || info.Key("return") == nil || info.Key("Q") != nil {
+		t.Fatal("unexpected keys")
+	}
+
+	if err = kb.SwitchPlane(KeyboardPlaneSymbols); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ = kb.Info(); info.Plane != KeyboardPlaneSymbols {
+		t.Fatalf("expected symbols, got '%s'", info.Plane)
+	}
+
+	if _, err = parseKeyboardInfo(&SourceNode{Type: "XCUIElementTypeApplication"}); err != errKeyboardNotShown {
+		t.Fatalf("expected %v, got %v", errKeyboardNotShown, err)
+	}
+}
+
+func TestKeyboard_TypeText(t *testing.T) {
+	wd := &fakeKeyboardDriver{plane: KeyboardPlaneLetters}
+	text := "Hi 2 [you]\n"
+	if err := NewKeyboard(wd).TypeText(text); err != nil {
+		t.Fatal(err)
+	}
+	if wd.typed != text {
+		t.Fatalf("expected %q, got %q", text, wd.typed)
+	}
+}
+
+func TestKeyboard_TapKey(t *testing.T) {
+	wd := &fakeKeyboardDriver{plane: KeyboardPlaneLetters}
+	if err := NewKeyboard(wd).TapKey("é"); !errors.Is(err, errNoSuchElement) {
+		t.Fatalf("expected %v, got %v", errNoSuchElement, err)
+	}
+	if wd.shift || wd.typed != "" {
+		t.Fatalf("expected shift to be released, got shift %v and %q typed", wd.shift, wd.typed)
+	}
+
+	// the letters cannot be reached from the symbols, the numbers can
+	wd = &fakeKeyboardDriver{plane: KeyboardPlaneSymbols, stuck: "more"}
+	if err := NewKeyboard(wd).TapKey("1"); err != nil || wd.typed != "1" {
+		t.Fatalf("expected to type through the numbers, got %q (%v)", wd.typed, err)
+	}
+}
@@ -0,0 +1,275 @@
+package gwda
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MjpegFrame A frame of the MJPEG stream.
+//  Data: the raw JPEG
+//  Image: the decoded JPEG, nil if decoding is disabled
+//  Timestamp: the time the frame was received
+//  Sequence: the number of the frame in the stream, starting with 0
+type MjpegFrame struct {
+	Data      []byte
+	Image     image.Image
+	Timestamp time.Time
+	Sequence  int
+}
+
+// MjpegStreamOption Configure the behavior of MjpegStream
+type MjpegStreamOption func(s *MjpegStream)
+
+// WithMjpegStreamDecode Whether to decode each frame into `image.Image`.
+//  Defaults to `true`
+func WithMjpegStreamDecode(b bool) MjpegStreamOption {
+	return func(s *MjpegStream) {
+		s.decode = b
+	}
+}
+
+// WithMjpegStreamBuffer The number of frames buffered by the channel
+// before frames are dropped for a slow reader.
+//  Defaults to `8`
+func WithMjpegStreamBuffer(n int) MjpegStreamOption {
+	return func(s *MjpegStream) {
+		if n >= 0 {
+			s.buffer = n
+		}
+	}
+}
+
+// MjpegStream Reads the screen of the device from the MJPEG server of WDA,
+// see `AppiumSettings.WithMjpegServerFramerate` to control the frame rate.
+type MjpegStream struct {
+	decode bool
+	buffer int
+
+	body    io.ReadCloser
+	frames  chan MjpegFrame
+	dropped int64
+	done    chan struct{}
+
+	mu     sync.Mutex
+	err    error
+	closed bool
+	// onClose Forgets the stream on the driver which closes it on `WebDriver.Close`
+	onClose func()
+}
+
+// NewMjpegStream Requests the MJPEG stream with `WebDriver.GetMjpegHTTPClient`.
+// The stream is closed by `WebDriver.Close` if not closed before.
+func NewMjpegStream(driver WebDriver, opts ...MjpegStreamOption) (*MjpegStream, error) {
+	// the client is tunneled to the MJPEG port, the host is not used
+	s, err := newMjpegStream(driver.GetMjpegHTTPClient(), "http://localhost/", opts...)
+	if err != nil {
+		return nil, err
+	}
+	if wd, ok := driver.(*remoteWD); ok {
+		wd.trackMjpegStream(s)
+	}
+	return s, nil
+}
+
+func newMjpegStream(client *http.Client, rawURL string, opts ...MjpegStreamOption) (s *MjpegStream, err error) {
+	s = &MjpegStream{
+		decode: true,
+		buffer: 8,
+		done:   make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	var resp *http.Response
+	if resp, err = client.Get(rawURL); err != nil {
+		return nil, fmt.Errorf("mjpeg stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("mjpeg stream: unexpected status '%s'", resp.Status)
+	}
+	var boundary string
+	if boundary, err = mjpegBoundary(resp.Header.Get("Content-Type")); err != nil {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("mjpeg stream: %w", err)
+	}
+
+	s.body = resp.Body
+	s.frames = make(chan MjpegFrame, s.buffer)
+	go s.read(newMjpegReader(resp.Body, boundary))
+	return s, nil
+}
+
+func mjpegBoundary(contentType string) (string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return "", fmt.Errorf("unexpected content type '%s'", mediaType)
+	}
+	if params["boundary"] == "" {
+		return "", errors.New("missing boundary")
+	}
+	return params["boundary"], nil
+}
+
+func (s *MjpegStream) read(r *mjpegReader) {
+	defer close(s.done)
+	defer close(s.frames)
+
+	for seq := 0; ; seq++ {
+		data, err := r.nextPart()
+		if err != nil {
+			s.mu.Lock()
+			if !s.closed && err != io.EOF {
+				s.err = err
+			}
+			s.mu.Unlock()
+			return
+		}
+		frame := MjpegFrame{Data: data, Timestamp: time.Now(), Sequence: seq}
+		if s.decode {
+			if frame.Image, err = jpeg.Decode(bytes.NewReader(data)); err != nil {
+				// a corrupted frame, most likely the stream has just started
+				atomic.AddInt64(&s.dropped, 1)
+				continue
+			}
+		}
+		select {
+		case s.frames <- frame:
+		default:
+			atomic.AddInt64(&s.dropped, 1)
+		}
+	}
+}
+
+// Frames Returns the channel of the frames, it is closed once the stream ends
+func (s *MjpegStream) Frames() <-chan MjpegFrame {
+	return s.frames
+}
+
+// Dropped The number of frames dropped because the channel was full or the JPEG was corrupted
+func (s *MjpegStream) Dropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+// Err Returns the error that ended the stream, if any
+func (s *MjpegStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close Stops reading and waits until the channel of frames is closed
+func (s *MjpegStream) Close() (err error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	onClose := s.onClose
+	s.mu.Unlock()
+
+	if onClose != nil {
+		onClose()
+	}
+	err = s.body.Close()
+	// unblock the reader so that it notices the closed body
+	go func() {
+		for range s.frames {
+		}
+	}()
+	<-s.done
+	return err
+}
+
+// mjpegReader Parses `multipart/x-mixed-replace` bodies.
+// Unlike mime/multipart it also accepts the delimiter of WDA,
+// which equals the boundary parameter (`--BoundaryString`) instead of being prefixed with `--`.
+type mjpegReader struct {
+	r          *bufio.Reader
+	delimiters [2]string
+	atPart     bool
+}
+
+func newMjpegReader(r io.Reader, boundary string) *mjpegReader {
+	return &mjpegReader{
+		r:          bufio.NewReaderSize(r, 64*1024),
+		delimiters: [2]string{"--" + boundary, boundary},
+	}
+}
+
+func (mr *mjpegReader) isDelimiter(line []byte) bool {
+	line = bytes.TrimRight(line, " \t\r\n")
+	for _, d := range mr.delimiters {
+		if string(line) == d || string(line) == d+"--" {
+			return true
+		}
+	}
+	return false
+}
+
+// nextPart Returns the body of the next part
+func (mr *mjpegReader) nextPart() (data []byte, err error) {
+	for !mr.atPart {
+		var line []byte
+		if line, err = mr.r.ReadBytes('\n'); err != nil {
+			return nil, err
+		}
+		mr.atPart = mr.isDelimiter(line)
+	}
+	mr.atPart = false
+
+	var header textproto.MIMEHeader
+	if header, err = textproto.NewReader(mr.r).ReadMIMEHeader(); err != nil {
+		return nil, err
+	}
+
+	if contentLength := header.Get("Content-Length"); contentLength != "" {
+		var n int
+		if n, err = strconv.Atoi(contentLength); err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid Content-Length '%s'", contentLength)
+		}
+		data = make([]byte, n)
+		if _, err = io.ReadFull(mr.r, data); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		return data, nil
+	}
+
+	// without Content-Length the part ends at the next delimiter
+	var buf bytes.Buffer
+	for {
+		var line []byte
+		if line, err = mr.r.ReadBytes('\n'); err != nil {
+			if err == io.EOF && buf.Len() != 0 {
+				return buf.Bytes(), nil
+			}
+			return nil, err
+		}
+		if mr.isDelimiter(line) {
+			mr.atPart = true
+			return bytes.TrimSuffix(buf.Bytes(), []byte("\r\n")), nil
+		}
+		buf.Write(line)
+	}
+}
@@ -0,0 +1,153 @@
+package gwda
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testJPEG(t *testing.T, c color.Color) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for i := 0; i < 64; i++ {
+		img.Set(i%8, i/8, c)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func Test_mjpegReader(t *testing.T) {
+	frame := testJPEG(t, color.White)
+	var body bytes.Buffer
+	// the delimiter of WDA equals the boundary parameter
+	fmt.Fprintf(&body, "--BoundaryString\r\nContent-type: image/jpg\r\nContent-Length: %d\r\n\r\n", len(frame))
+	body.Write(frame)
+	// a part without Content-Length
+	body.WriteString("\r\n\r\n--BoundaryString\r\nContent-type: image/jpg\r\n\r\n")
+	body.Write(frame)
+	body.WriteString("\r\n--BoundaryString--\r\n")
+
+	mr := newMjpegReader(&body, "--BoundaryString")
+	for i := 0; i < 2; i++ {
+		data, err := mr.nextPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		if !bytes.Equal(data, frame) {
+			t.Fatalf("part %d: got %d bytes, expected %d", i, len(data), len(frame))
+		}
+	}
+	if _, err := mr.nextPart(); err == nil {
+		t.Fatal("expected the end of the stream")
+	}
+}
+
+func TestMjpegStream(t *testing.T) {
+	frames := [][]byte{testJPEG(t, color.White), []byte("corrupted"), testJPEG(t, color.Black)}
+	stop := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary=--BoundaryString")
+		for _, frame := range frames {
+			fmt.Fprintf(w, "--BoundaryString\r\nContent-type: image/jpg\r\nContent-Length: %d\r\n\r\n", len(frame))
+			_, _ = w.Write(frame)
+			_, _ = w.Write([]byte("\r\n\r\n"))
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-stop:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(stop)
+
+	s, err := newMjpegStream(srv.Client(), srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received []MjpegFrame
+	timeout := time.After(5 * time.Second)
+	for len(received) < 2 {
+		select {
+		case frame := <-s.Frames():
+			received = append(received, frame)
+		case <-timeout:
+			t.Fatalf("received %d frames", len(received))
+		}
+	}
+	if received[0].Image == nil || received[1].Sequence != 2 || received[1].Timestamp.Before(received[0].Timestamp) {
+		t.Fatalf("unexpected frames: %+v", received)
+	}
+	if s.Dropped() != 1 {
+		t.Fatalf("expected 1 dropped frame, got %d", s.Dropped())
+	}
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-s.Frames(); ok {
+		t.Fatal("expected the channel to be closed")
+	}
+	if s.Err() != nil {
+		t.Fatal(s.Err())
+	}
+
+	if _, err = mjpegBoundary("image/jpeg"); err == nil || !strings.Contains(err.Error(), "content type") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewMjpegStream_closedByDriver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary=--BoundaryString")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	conn, _ := net.Pipe()
+	wd := &remoteWD{mjpegConn: conn, mjpegClient: &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+	}}}
+
+	closed, err := NewMjpegStream(wd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	open, err := NewMjpegStream(wd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = closed.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(wd.mjpegStreams) != 1 {
+		t.Fatalf("expected the closed stream to be forgotten, got %d streams", len(wd.mjpegStreams))
+	}
+
+	if err = wd.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case _, ok := <-open.Frames():
+		if ok {
+			t.Fatal("unexpected frame")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the stream to be closed with the driver")
+	}
+	if len(wd.mjpegStreams) != 0 {
+		t.Fatalf("expected no streams left, got %d", len(wd.mjpegStreams))
+	}
+}
@@ -0,0 +1,317 @@
+package gwda
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RecordingOption Configure the behavior of ScreenRecorder
+type RecordingOption func(r *ScreenRecorder)
+
+// WithRecordingFPS The frame rate of the recording.
+// Frames are repeated or skipped based on their timestamps to keep this rate.
+//  Defaults to `10`
+func WithRecordingFPS(fps int) RecordingOption {
+	return func(r *ScreenRecorder) {
+		if fps > 0 {
+			r.fps = fps
+		}
+	}
+}
+
+// RecordingInfo The result of a recording
+type RecordingInfo struct {
+	Path     string
+	FPS      int
+	Frames   int
+	Duration time.Duration
+	// Dropped The number of frames the MJPEG stream dropped
+	Dropped int64
+}
+
+// ScreenRecorder Records the MJPEG stream of the device.
+// A path ending with `.avi` is written as Motion JPEG AVI,
+// any other path is created as a directory of JPEG images with a `manifest.json`.
+type ScreenRecorder struct {
+	path string
+	fps  int
+
+	stream     *MjpegStream
+	normalizer *frameNormalizer
+	sink       frameSink
+	done       chan struct{}
+
+	mu  sync.Mutex
+	err error
+	// streamErr Why the stream ended before Stop, the recording is truncated at streamEnd
+	streamErr error
+	streamEnd time.Time
+	stopping  bool
+
+	stopOnce sync.Once
+	info     RecordingInfo
+	stopErr  error
+}
+
+// NewScreenRecorder Starts recording into the path
+func NewScreenRecorder(driver WebDriver, path string, opts ...RecordingOption) (r *ScreenRecorder, err error) {
+	r = &ScreenRecorder{path: path, fps: 10, done: make(chan struct{})}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.stream, err = NewMjpegStream(driver, WithMjpegStreamDecode(false), WithMjpegStreamBuffer(32)); err != nil {
+		return nil, err
+	}
+	r.normalizer = &frameNormalizer{fps: r.fps, write: r.writeFrame}
+	go r.record()
+	return r, nil
+}
+
+func (r *ScreenRecorder) record() {
+	defer close(r.done)
+	for frame := range r.stream.Frames() {
+		if err := r.normalizer.add(frame.Data, frame.Timestamp); err != nil {
+			r.mu.Lock()
+			r.err = err
+			r.mu.Unlock()
+			_ = r.stream.Close()
+			return
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.stopping {
+		return
+	}
+	r.streamEnd = time.Now()
+	if err := r.stream.Err(); err != nil {
+		r.streamErr = fmt.Errorf("recording: mjpeg stream: %w", err)
+	} else {
+		r.streamErr = errors.New("recording: mjpeg stream ended before Stop")
+	}
+}
+
+func (r *ScreenRecorder) writeFrame(data []byte, ts time.Time) (err error) {
+	if r.sink == nil {
+		var cfg image.Config
+		if cfg, err = jpeg.DecodeConfig(bytes.NewReader(data)); err != nil {
+			return fmt.Errorf("recording: %w", err)
+		}
+		if strings.EqualFold(filepath.Ext(r.path), ".avi") {
+			var aw *aviWriter
+			if aw, err = newAviWriter(r.path, cfg.Width, cfg.Height, r.fps); err != nil {
+				return err
+			}
+			r.sink = aviSink{aw}
+		} else {
+			if err = os.MkdirAll(r.path, 0755); err != nil {
+				return err
+			}
+			r.sink = &imageSequence{dir: r.path, FPS: r.fps, Width: cfg.Width, Height: cfg.Height}
+		}
+	}
+	return r.sink.writeFrame(data, ts)
+}
+
+// Stop Stops recording and finishes the file.
+// If the MJPEG stream ended before, the file is finished up to its end and the error of the stream is returned.
+func (r *ScreenRecorder) Stop() (RecordingInfo, error) {
+	r.stopOnce.Do(func() {
+		r.info, r.stopErr = r.stop()
+	})
+	return r.info, r.stopErr
+}
+
+func (r *ScreenRecorder) stop() (info RecordingInfo, err error) {
+	end := time.Now()
+	r.mu.Lock()
+	r.stopping = true
+	r.mu.Unlock()
+	_ = r.stream.Close()
+	<-r.done
+
+	r.mu.Lock()
+	err = r.err
+	streamErr := r.streamErr
+	if streamErr != nil {
+		end = r.streamEnd
+	}
+	r.mu.Unlock()
+	if streamErr != nil {
+		// the recording is finished up to the end of the stream, but reported as truncated
+		defer func() {
+			if err != nil {
+				err = fmt.Errorf("%w, then %v", streamErr, err)
+			} else {
+				err = streamErr
+			}
+		}()
+	}
+	if err == nil {
+		err = r.normalizer.flush(end)
+	}
+	if r.sink == nil {
+		if err == nil {
+			err = errors.New("recording: no frame received")
+		}
+		return RecordingInfo{}, err
+	}
+	if e := r.sink.close(); err == nil {
+		err = e
+	}
+	info = RecordingInfo{
+		Path:     r.path,
+		FPS:      r.fps,
+		Frames:   r.normalizer.written,
+		Duration: time.Duration(r.normalizer.written) * time.Second / time.Duration(r.fps),
+		Dropped:  r.stream.Dropped(),
+	}
+	return
+}
+
+// Discard Stops recording and removes the file
+func (r *ScreenRecorder) Discard() error {
+	_, _ = r.Stop()
+	return os.RemoveAll(r.path)
+}
+
+// frameNormalizer Turns frames received at irregular times into a constant frame rate:
+// each slot of `1/fps` seconds shows the latest frame received before its end.
+type frameNormalizer struct {
+	fps   int
+	write func(data []byte, ts time.Time) error
+
+	start   time.Time
+	written int
+	prev    []byte
+	prevTS  time.Time
+}
+
+func (fn *frameNormalizer) slot(ts time.Time) int {
+	return int(math.Floor(ts.Sub(fn.start).Seconds() * float64(fn.fps)))
+}
+
+func (fn *frameNormalizer) fill(slot int) error {
+	for ; fn.written < slot; fn.written++ {
+		if err := fn.write(fn.prev, fn.prevTS); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fn *frameNormalizer) add(data []byte, ts time.Time) error {
+	if fn.prev == nil {
+		fn.start = ts
+	} else if err := fn.fill(fn.slot(ts)); err != nil {
+		return err
+	}
+	fn.prev, fn.prevTS = data, ts
+	return nil
+}
+
+// flush Repeats the last frame until the end of the recording
+func (fn *frameNormalizer) flush(end time.Time) error {
+	if fn.prev == nil {
+		return nil
+	}
+	return fn.fill(fn.slot(end) + 1)
+}
+
+type frameSink interface {
+	writeFrame(data []byte, ts time.Time) error
+	close() error
+}
+
+type aviSink struct{ *aviWriter }
+
+func (s aviSink) writeFrame(data []byte, _ time.Time) error { return s.aviWriter.writeFrame(data) }
+
+// imageSequence Writes each distinct frame once and lists every slot in `manifest.json`
+type imageSequence struct {
+	dir string
+
+	FPS    int `json:"fps"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	Frames []struct {
+		File      string    `json:"file"`
+		Timestamp time.Time `json:"timestamp"`
+	} `json:"frames"`
+
+	images int
+	last   []byte
+	file   string
+}
+
+func (s *imageSequence) writeFrame(data []byte, ts time.Time) error {
+	if s.file == "" || !bytes.Equal(data, s.last) {
+		s.images++
+		s.file = fmt.Sprintf("frame_%06d.jpg", s.images)
+		if err := ioutil.WriteFile(filepath.Join(s.dir, s.file), data, 0644); err != nil {
+			return err
+		}
+		s.last = data
+	}
+	s.Frames = append(s.Frames, struct {
+		File      string    `json:"file"`
+		Timestamp time.Time `json:"timestamp"`
+	}{s.file, ts})
+	return nil
+}
+
+func (s *imageSequence) close() error {
+	bsJSON, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(s.dir, "manifest.json"), bsJSON, 0644)
+}
+
+// TestingT The subset of `testing.TB` used by RecordOnFailure
+type TestingT interface {
+	Name() string
+	Failed() bool
+	Cleanup(func())
+	Logf(format string, args ...interface{})
+}
+
+var regexpUnsafeFileName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// RecordOnFailure Records the screen during the test into `dir/<test name>.avi`
+// and keeps the recording only if the test fails.
+func RecordOnFailure(t TestingT, driver WebDriver, dir string, opts ...RecordingOption) (err error) {
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	name := filepath.Join(dir, regexpUnsafeFileName.ReplaceAllString(t.Name(), "_")+".avi")
+	var recorder *ScreenRecorder
+	if recorder, err = NewScreenRecorder(driver, name, opts...); err != nil {
+		return err
+	}
+	t.Cleanup(func() {
+		if !t.Failed() {
+			_ = recorder.Discard()
+			return
+		}
+		if info, err := recorder.Stop(); err != nil {
+			t.Logf("screen recording '%s': %v", name, err)
+		} else {
+			t.Logf("screen recording: %s (%d frames, %s)", info.Path, info.Frames, info.Duration)
+		}
+	})
+	return nil
+}
@@ -0,0 +1,230 @@
+package gwda
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_frameNormalizer(t *testing.T) {
+	var written []string
+	fn := &frameNormalizer{fps: 10, write: func(data []byte, _ time.Time) error {
+		written = append(written, string(data))
+		return nil
+	}}
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	// a: 0-250ms, b is replaced by c within the same slot, c: 260-500ms
+	for _, f := range []struct {
+		data string
+		ms   int
+	}{{"a", 0}, {"b", 250}, {"c", 260}} {
+		if err := fn.add([]byte(f.data), at(f.ms)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := fn.flush(at(500)); err != nil {
+		t.Fatal(err)
+	}
+	if actual := strings.Join(written, ""); actual != "aacccc" {
+		t.Fatalf("expected 'aacccc', got '%s'", actual)
+	}
+}
+
+func Test_aviWriter(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "test.avi")
+	aw, err := newAviWriter(name, 8, 8, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := testJPEG(t, color.White)
+	for i := 0; i < 3; i++ {
+		if err = aw.writeFrame(frame); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = aw.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u32 := func(offset int) int { return int(binary.LittleEndian.Uint32(data[offset:])) }
+	if string(data[:4]) != "RIFF" || string(data[8:12]) != "AVI " || u32(aviOffsetRIFFSize) != len(data)-8 {
+		t.Fatal("invalid RIFF header")
+	}
+	if u32(aviOffsetAvihFrames) != 3 || u32(aviOffsetStrhLength) != 3 || u32(32) != 200000 {
+		t.Fatal("invalid frame count or rate")
+	}
+	moviEnd := aviHeaderSize - 4 + u32(aviHeaderSize-8)
+	if string(data[moviEnd:moviEnd+4]) != "idx1" || u32(moviEnd+4) != 3*16 || len(data) != moviEnd+8+3*16 {
+		t.Fatal("invalid index")
+	}
+	// the index points at the chunks, relative to 'movi'
+	if offset := aviHeaderSize - 4 + u32(moviEnd+8+16+8); string(data[offset:offset+4]) != "00dc" || u32(offset+4) != len(frame) {
+		t.Fatal("invalid index entry")
+	}
+}
+
+type fakeRecordingDriver struct {
+	WebDriver
+	client *http.Client
+}
+
+func (wd fakeRecordingDriver) GetMjpegHTTPClient() *http.Client { return wd.client }
+
+type fakeT struct {
+	failed   bool
+	cleanups []func()
+	logs     []string
+}
+
+func (t *fakeT) Name() string      { return "TestLogin/valid user" }
+func (t *fakeT) Failed() bool      { return t.failed }
+func (t *fakeT) Cleanup(fn func()) { t.cleanups = append(t.cleanups, fn) }
+func (t *fakeT) Logf(format string, args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+func (t *fakeT) cleanup() {
+	for _, fn := range t.cleanups {
+		fn()
+	}
+}
+
+func TestRecordOnFailure(t *testing.T) {
+	frame := testJPEG(t, color.White)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary=--BoundaryString")
+		for {
+			fmt.Fprintf(w, "--BoundaryString\r\nContent-type: image/jpg\r\nContent-Length: %d\r\n\r\n", len(frame))
+			_, _ = w.Write(frame)
+			_, _ = w.Write([]byte("\r\n\r\n"))
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(20 * time.Millisecond):
+			}
+		}
+	}))
+	defer srv.Close()
+	driver := fakeRecordingDriver{client: &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+	}}}
+
+	dir := t.TempDir()
+	for _, failed := range []bool{false, true} {
+		ft := &fakeT{}
+		if err := RecordOnFailure(ft, driver, dir); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(300 * time.Millisecond)
+		ft.failed = failed
+		ft.cleanup()
+
+		name := filepath.Join(dir, "TestLogin_valid_user.avi")
+		_, err := os.Stat(name)
+		if !failed && !os.IsNotExist(err) {
+			t.Fatalf("expected the recording of a passed test to be removed: %v", err)
+		}
+		if failed && (err != nil || len(ft.logs) != 1 || !strings.Contains(ft.logs[0], name)) {
+			t.Fatalf("expected the recording of a failed test to be kept: %v %v", err, ft.logs)
+		}
+	}
+}
+
+func TestScreenRecorder_imageSequence(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "frames")
+	r := &ScreenRecorder{path: dir, fps: 10}
+	frame := testJPEG(t, color.White)
+	for _, data := range [][]byte{frame, frame, testJPEG(t, color.Black)} {
+		if err := r.writeFrame(data, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.sink.close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "manifest.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest struct {
+		FPS    int
+		Width  int
+		Frames []struct{ File string }
+	}
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.FPS != 10 || manifest.Width != 8 || len(manifest.Frames) != 3 ||
+		manifest.Frames[1].File != "frame_000001.jpg" || manifest.Frames[2].File != "frame_000002.jpg" {
+		t.Fatalf("unexpected manifest: %s", data)
+	}
+}
+
+func TestScreenRecorder_streamEnded(t *testing.T) {
+	frame := testJPEG(t, color.White)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary=--BoundaryString")
+		for i := 0; i < 5; i++ {
+			fmt.Fprintf(w, "--BoundaryString\r\nContent-type: image/jpg\r\nContent-Length: %d\r\n\r\n", len(frame))
+			_, _ = w.Write(frame)
+			_, _ = w.Write([]byte("\r\n\r\n"))
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+		// the device goes away in the middle of a frame
+		fmt.Fprintf(w, "--BoundaryString\r\nContent-type: image/jpg\r\nContent-Length: %d\r\n\r\n", len(frame))
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}))
+	defer srv.Close()
+	driver := fakeRecordingDriver{client: &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, srv.Listener.Addr().String())
+		},
+	}}}
+
+	name := filepath.Join(t.TempDir(), "truncated.avi")
+	r, err := NewScreenRecorder(driver, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-r.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the stream to end")
+	}
+	time.Sleep(200 * time.Millisecond)
+	info, err := r.Stop()
+	if err == nil || !strings.Contains(err.Error(), "mjpeg stream") {
+		t.Fatalf("expected the error of the stream, got %v", err)
+	}
+	// the frames are not padded until Stop
+	if info.Frames == 0 || info.Frames > 3 {
+		t.Fatalf("unexpected frames: %+v", info)
+	}
+	if _, err = os.Stat(name); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,158 @@
+package scenario
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Status The outcome of a step
+type Status string
+
+const (
+	StatusPassed Status = "passed"
+	StatusFailed Status = "failed"
+	// StatusSkipped The condition of the step did not hold, an optional step failed,
+	// or the step did not run because of a failure
+	StatusSkipped Status = "skipped"
+)
+
+// StepResult The outcome of a step.
+//  Index: the 1-based position, e.g. `3.1` for nested steps and `4[2].1` for the second iteration of a loop
+//  Error: why the step failed or was skipped
+//  Screenshot: the path of the screenshot taken on failure
+type StepResult struct {
+	Index      string   `json:"index"`
+	Name       string   `json:"name"`
+	Action     string   `json:"action"`
+	Status     Status   `json:"status"`
+	Duration   Duration `json:"duration"`
+	Error      string   `json:"error,omitempty"`
+	Screenshot string   `json:"screenshot,omitempty"`
+}
+
+// Result The outcome of a scenario
+type Result struct {
+	Name      string       `json:"name"`
+	Passed    bool         `json:"passed"`
+	StartTime time.Time    `json:"startTime"`
+	Duration  Duration     `json:"duration"`
+	Error     string       `json:"error,omitempty"`
+	Steps     []StepResult `json:"steps"`
+}
+
+// count Returns the number of steps with the status
+func (r *Result) count(status Status) (n int) {
+	for _, step := range r.Steps {
+		if step.Status == status {
+			n++
+		}
+	}
+	return
+}
+
+// MarshalJSON Writes the duration in seconds
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return []byte(seconds(d)), nil
+}
+
+func seconds(d Duration) string {
+	return strconv.FormatFloat(time.Duration(d).Seconds(), 'f', 3, 64)
+}
+
+// WriteJSON Writes the results as a JSON array
+func WriteJSON(w io.Writer, results ...*Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if results == nil {
+		results = []*Result{}
+	}
+	return enc.Encode(results)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Skipped   *junitMessage `xml:"skipped"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit Writes the results as JUnit XML, with a test suite per scenario and a test case per step.
+// Failure screenshots are attached as `[[ATTACHMENT|path]]`, which Jenkins and GitLab pick up.
+func WriteJUnit(w io.Writer, results ...*Result) error {
+	var suites junitTestSuites
+	var total time.Duration
+	for _, result := range results {
+		suite := junitTestSuite{
+			Name:      result.Name,
+			Tests:     len(result.Steps),
+			Failures:  result.count(StatusFailed),
+			Skipped:   result.count(StatusSkipped),
+			Time:      seconds(result.Duration),
+			Timestamp: result.StartTime.Format("2006-01-02T15:04:05"),
+		}
+		for _, step := range result.Steps {
+			tc := junitTestCase{
+				Name:      fmt.Sprintf("%s %s", step.Index, step.Name),
+				ClassName: result.Name,
+				Time:      seconds(step.Duration),
+			}
+			switch step.Status {
+			case StatusFailed:
+				tc.Failure = &junitMessage{Message: step.Error, Text: step.Error}
+			case StatusSkipped:
+				tc.Skipped = &junitMessage{Message: step.Error}
+			}
+			if step.Screenshot != "" {
+				tc.SystemOut = "[[ATTACHMENT|" + step.Screenshot + "]]"
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		suites.Suites = append(suites.Suites, suite)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		total += time.Duration(result.Duration)
+	}
+	suites.Time = seconds(Duration(total))
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,72 @@
+package scenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testResult() *Result {
+	return &Result{
+		Name:      "Login",
+		StartTime: time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC),
+		Duration:  Duration(2500 * time.Millisecond),
+		Error:     `step 2: element not found`,
+		Steps: []StepResult{
+			{Index: "1", Name: "launch com.example.app", Action: "launch", Status: StatusPassed, Duration: Duration(time.Second)},
+			{Index: "2", Name: "tap name \"login\"", Action: "tap", Status: StatusFailed, Duration: Duration(1500 * time.Millisecond),
+				Error: "element not found", Screenshot: "Login-step-2.png"},
+			{Index: "3", Name: "screenshot", Action: "screenshot", Status: StatusSkipped, Error: "not run"},
+		},
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJUnit(&buf, testResult()); err != nil {
+		t.Fatal(err)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatal(err)
+	}
+	if suites.Tests != 3 || suites.Failures != 1 || suites.Skipped != 1 || suites.Time != "2.500" {
+		t.Fatalf("unexpected suites: %+v", suites)
+	}
+	suite := suites.Suites[0]
+	if suite.Name != "Login" || suite.Timestamp != "2021-06-01T12:00:00" || len(suite.Cases) != 3 {
+		t.Fatalf("unexpected suite: %+v", suite)
+	}
+	failed := suite.Cases[1]
+	if failed.Name != `2 tap name "login"` || failed.Failure == nil || failed.Failure.Message != "element not found" ||
+		failed.SystemOut != "[[ATTACHMENT|Login-step-2.png]]" || failed.Time != "1.500" {
+		t.Fatalf("unexpected failed case: %+v", failed)
+	}
+	if suite.Cases[0].Failure != nil || suite.Cases[2].Skipped == nil {
+		t.Fatalf("unexpected cases: %+v", suite.Cases)
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Fatal("expected the XML header")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, testResult()); err != nil {
+		t.Fatal(err)
+	}
+	var results []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0]["duration"] != 2.5 || results[0]["passed"] != false {
+		t.Fatalf("unexpected results: %v", results)
+	}
+	steps := results[0]["steps"].([]interface{})
+	if step := steps[1].(map[string]interface{}); step["status"] != "failed" || step["screenshot"] != "Login-step-2.png" {
+		t.Fatalf("unexpected step: %v", step)
+	}
+}
@@ -0,0 +1,657 @@
+package scenario
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/electricbubble/gwda"
+)
+
+// DefaultTimeout The timeout of the steps if neither the scenario nor the step has one
+const DefaultTimeout = 10 * time.Second
+
+// Option Configure the behavior of Run
+type Option func(r *runner)
+
+// WithVars Sets variables, overriding the variables of the scenario
+func WithVars(vars map[string]string) Option {
+	return func(r *runner) {
+		for k, v := range vars {
+			r.overrides[k] = v
+		}
+	}
+}
+
+// WithOutputDir The directory of the screenshots with relative paths and of the failure screenshots.
+//  Defaults to the current directory
+func WithOutputDir(dir string) Option {
+	return func(r *runner) {
+		r.outputDir = dir
+	}
+}
+
+// WithFailureScreenshots Whether to save a screenshot when a step fails.
+//  Defaults to `true`
+func WithFailureScreenshots(b bool) Option {
+	return func(r *runner) {
+		r.failureScreenshots = b
+	}
+}
+
+// WithPollInterval The interval between lookups of elements and checks of conditions.
+//  Defaults to `500ms`
+func WithPollInterval(interval time.Duration) Option {
+	return func(r *runner) {
+		r.interval = interval
+	}
+}
+
+// WithLogger Logs each step once it has finished
+func WithLogger(logf func(format string, args ...interface{})) Option {
+	return func(r *runner) {
+		r.logf = logf
+	}
+}
+
+type runner struct {
+	driver             gwda.WebDriver
+	vars               map[string]string
+	overrides          map[string]string
+	outputDir          string
+	failureScreenshots bool
+	interval           time.Duration
+	logf               func(format string, args ...interface{})
+
+	scenario *Scenario
+	timeout  time.Duration
+	result   *Result
+}
+
+// stepError The failure of a step, which groups of steps pass on as it is
+type stepError struct {
+	index string
+	err   error
+}
+
+func (e *stepError) Error() string {
+	return fmt.Sprintf("step %s: %v", e.index, e.err)
+}
+
+func (e *stepError) Unwrap() error {
+	return e.err
+}
+
+func isStepError(err error) bool {
+	var se *stepError
+	return errors.As(err, &se)
+}
+
+// Run Runs the steps of the scenario in order until a step fails.
+// The failure is reported by the result, along with the outcome of each step.
+func Run(driver gwda.WebDriver, scenario *Scenario, opts ...Option) *Result {
+	r := &runner{
+		driver:             driver,
+		vars:               map[string]string{"scenario": scenario.Name},
+		overrides:          make(map[string]string),
+		outputDir:          ".",
+		failureScreenshots: true,
+		interval:           500 * time.Millisecond,
+		logf:               func(format string, args ...interface{}) {},
+		scenario:           scenario,
+		timeout:            time.Duration(scenario.Timeout),
+		result:             &Result{Name: scenario.Name, StartTime: time.Now()},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.timeout <= 0 {
+		r.timeout = DefaultTimeout
+	}
+	for k, v := range scenario.Vars {
+		r.vars[k] = v
+	}
+	for k, v := range r.overrides {
+		r.vars[k] = v
+	}
+
+	err := r.runSteps(scenario.Steps, "")
+	r.result.Passed = err == nil
+	if err != nil {
+		r.result.Error = err.Error()
+	}
+	r.result.Duration = Duration(time.Since(r.result.StartTime))
+	return r.result
+}
+
+func (r *runner) runSteps(steps []Step, prefix string) error {
+	for i := range steps {
+		if err := r.runStep(&steps[i], prefix+strconv.Itoa(i+1)); err != nil {
+			// only the top level lists the steps that did not run
+			if prefix == "" {
+				for j := i + 1; j < len(steps); j++ {
+					r.result.Steps = append(r.result.Steps, StepResult{
+						Index:  strconv.Itoa(j + 1),
+						Name:   r.stepName(&steps[j]),
+						Action: steps[j].actions()[0],
+						Status: StatusSkipped,
+						Error:  "not run",
+					})
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *runner) stepName(step *Step) string {
+	if step.Name != "" {
+		return r.expand(step.Name)
+	}
+	return step.describe()
+}
+
+// runStep Runs the step and records its result, returning an error if the scenario should stop
+func (r *runner) runStep(step *Step, index string) (err error) {
+	startTime := time.Now()
+	pos := len(r.result.Steps)
+	r.result.Steps = append(r.result.Steps, StepResult{Index: index, Name: r.stepName(step), Action: step.actions()[0]})
+
+	timeout := r.timeout
+	if step.Timeout > 0 {
+		timeout = time.Duration(step.Timeout)
+	}
+
+	skipped := false
+	if step.If != nil {
+		var ok bool
+		if ok, _, err = r.check(step.If); err == nil && !ok {
+			if len(step.Else) != 0 {
+				err = r.runSteps(step.Else, index+".")
+			} else {
+				skipped = true
+			}
+		}
+		if err == nil && ok {
+			err = r.do(step, index, timeout)
+		}
+	} else {
+		err = r.do(step, index, timeout)
+	}
+
+	sr := &r.result.Steps[pos]
+	sr.Duration = Duration(time.Since(startTime))
+	switch {
+	case skipped:
+		sr.Status = StatusSkipped
+		sr.Error = "condition not met"
+	case err == nil:
+		sr.Status = StatusPassed
+	case step.Optional:
+		sr.Status = StatusSkipped
+		sr.Error = "optional step failed: " + err.Error()
+		err = nil
+	default:
+		sr.Status = StatusFailed
+		sr.Error = err.Error()
+		// a nested step has saved the screenshot already
+		if r.failureScreenshots && !isStepError(err) {
+			sr.Screenshot = r.failureScreenshot(index)
+		}
+	}
+	r.logf("[%s] %s %s (%v)%s", index, sr.Status, sr.Name, time.Duration(sr.Duration).Round(time.Millisecond), suffix(sr.Error))
+
+	if err != nil && !isStepError(err) {
+		return &stepError{index: index, err: err}
+	}
+	return err
+}
+
+func suffix(s string) string {
+	if s == "" {
+		return ""
+	}
+	return ": " + s
+}
+
+func (r *runner) failureScreenshot(index string) string {
+	name := fmt.Sprintf("%s-step-%s.png", sanitize(r.scenario.Name), index)
+	path, err := r.saveScreenshot(name)
+	if err != nil {
+		r.logf("[%s] failure screenshot: %v", index, err)
+		return ""
+	}
+	return path
+}
+
+var unsafeChars = regexp.MustCompile(`[^\w.-]+`)
+
+func sanitize(name string) string {
+	if name = strings.Trim(unsafeChars.ReplaceAllString(name, "_"), "_"); name == "" {
+		name = "scenario"
+	}
+	return name
+}
+
+func (r *runner) saveScreenshot(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.outputDir, path)
+	}
+	raw, err := r.driver.Screenshot()
+	if err != nil {
+		return "", err
+	}
+	data := raw.Bytes()
+	// WDA returns JPEG with `screenshotQuality` set
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("decode screenshot: %w", err)
+	}
+	if format != "png" {
+		var buf bytes.Buffer
+		if err = png.Encode(&buf, img); err != nil {
+			return "", err
+		}
+		data = buf.Bytes()
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	return path, ioutil.WriteFile(path, data, 0644)
+}
+
+var varPattern = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// expand Substitutes the variables, unknown variables are kept as they are
+func (r *runner) expand(s string) string {
+	return varPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		if v, ok := r.vars[ref[2:len(ref)-1]]; ok {
+			return v
+		}
+		return ref
+	})
+}
+
+// do Runs the action of the step
+func (r *runner) do(step *Step, index string, timeout time.Duration) (err error) {
+	switch {
+	case step.Launch != "":
+		return r.driver.AppLaunch(r.expand(step.Launch))
+	case step.Terminate != "":
+		_, err = r.driver.AppTerminate(r.expand(step.Terminate))
+		return err
+	case step.Tap != nil:
+		if step.Tap.isPoint() {
+			return r.driver.TapFloat(deref(step.Tap.X), deref(step.Tap.Y))
+		}
+		var elem gwda.WebElement
+		if elem, err = r.find(*step.Tap, timeout); err != nil {
+			return err
+		}
+		return elem.Click()
+	case step.Type != nil:
+		text := r.expand(step.Type.Text)
+		if step.Type.Target.isEmpty() {
+			return r.driver.SendKeys(text)
+		}
+		var elem gwda.WebElement
+		if elem, err = r.find(step.Type.Target, timeout); err != nil {
+			return err
+		}
+		if step.Type.Clear {
+			if err = elem.Clear(); err != nil {
+				return err
+			}
+		}
+		return elem.SendKeys(text)
+	case step.Swipe != nil:
+		return r.swipe(step.Swipe, timeout)
+	case step.Press != "":
+		button := gwda.DeviceButton(r.expand(step.Press))
+		switch button {
+		case gwda.DeviceButtonHome, gwda.DeviceButtonVolumeUp, gwda.DeviceButtonVolumeDown:
+			return r.driver.PressButton(button)
+		}
+		return fmt.Errorf("unknown button %q", button)
+	case step.Alert != nil:
+		if step.Alert.SendKeys != "" {
+			if err = r.driver.AlertSendKeys(r.expand(step.Alert.SendKeys)); err != nil {
+				return err
+			}
+		}
+		switch {
+		case step.Alert.Accept != nil:
+			return r.driver.AlertAccept(labels(r.expand(*step.Alert.Accept))...)
+		case step.Alert.Dismiss != nil:
+			return r.driver.AlertDismiss(labels(r.expand(*step.Alert.Dismiss))...)
+		}
+		return nil
+	case step.Wait != nil:
+		deadline := time.Now().Add(timeout)
+		for {
+			ok, why, err := r.check(step.Wait)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return nil
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("timeout after %v: %s", timeout, why)
+			}
+			time.Sleep(r.interval)
+		}
+	case step.Assert != nil:
+		ok, why, err := r.check(step.Assert)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("assertion failed: %s", why)
+		}
+		return nil
+	case step.Screenshot != "":
+		_, err = r.saveScreenshot(r.expand(step.Screenshot))
+		return err
+	case step.Sleep != 0:
+		time.Sleep(time.Duration(step.Sleep))
+		return nil
+	case step.Set != nil:
+		// in order, so that a value may refer to a variable set before
+		for _, assignment := range step.Set {
+			r.vars[assignment.Name] = r.expand(assignment.Value)
+		}
+		return nil
+	case step.Store != nil:
+		var elem gwda.WebElement
+		if elem, err = r.find(step.Store.Target, timeout); err != nil {
+			return err
+		}
+		var value string
+		if step.Store.Attribute != "" {
+			value, err = elem.GetAttribute(gwda.ElementAttribute{step.Store.Attribute: ""})
+		} else {
+			value, err = elem.Text()
+		}
+		if err != nil {
+			return err
+		}
+		r.vars[step.Store.Var] = value
+		return nil
+	case step.Repeat != nil:
+		return r.repeat(step.Repeat, index)
+	case step.ForEach != nil:
+		name := step.ForEach.Var
+		if name == "" {
+			name = "item"
+		}
+		defer r.scopeVars(name, "index")()
+		for i, item := range step.ForEach.Items {
+			r.vars[name] = r.expand(item)
+			r.vars["index"] = strconv.Itoa(i + 1)
+			if err = r.runSteps(step.ForEach.Steps, fmt.Sprintf("%s[%d].", index, i+1)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case step.Steps != nil:
+		return r.runSteps(step.Steps, index+".")
+	}
+	return fmt.Errorf("no action")
+}
+
+func labels(label string) []string {
+	if label == "" {
+		return nil
+	}
+	return []string{label}
+}
+
+func (r *runner) repeat(repeat *RepeatAction, index string) (err error) {
+	max := repeat.Times
+	if max <= 0 {
+		max = 100
+	}
+	defer r.scopeVars("index")()
+	for i := 1; i <= max; i++ {
+		if repeat.While != nil || repeat.Until != nil {
+			var ok bool
+			cond, expected := repeat.While, true
+			if cond == nil {
+				cond, expected = repeat.Until, false
+			}
+			if ok, _, err = r.check(cond); err != nil {
+				return err
+			}
+			if ok != expected {
+				return nil
+			}
+		}
+		r.vars["index"] = strconv.Itoa(i)
+		if err = r.runSteps(repeat.Steps, fmt.Sprintf("%s[%d].", index, i)); err != nil {
+			return err
+		}
+	}
+	if repeat.Times <= 0 {
+		return fmt.Errorf("condition still holds after %d iterations", max)
+	}
+	return nil
+}
+
+// scopeVars Saves the variables of a loop, the returned function restores them,
+// so that an outer loop sees its own `${index}` again after an inner loop
+func (r *runner) scopeVars(names ...string) (restore func()) {
+	saved := make(map[string]string, len(names))
+	for _, name := range names {
+		if v, ok := r.vars[name]; ok {
+			saved[name] = v
+		}
+	}
+	return func() {
+		for _, name := range names {
+			if v, ok := saved[name]; ok {
+				r.vars[name] = v
+			} else {
+				delete(r.vars, name)
+			}
+		}
+	}
+}
+
+func (r *runner) swipe(swipe *SwipeAction, timeout time.Duration) (err error) {
+	if swipe.Direction == "" {
+		return r.driver.SwipeFloat(swipe.From[0], swipe.From[1], swipe.To[0], swipe.To[1])
+	}
+	direction := gwda.Direction(r.expand(swipe.Direction))
+	if !swipe.Target.isEmpty() {
+		var elem gwda.WebElement
+		if elem, err = r.find(swipe.Target, timeout); err != nil {
+			return err
+		}
+		return elem.SwipeDirection(direction)
+	}
+
+	var size gwda.Size
+	if size, err = r.driver.WindowSize(); err != nil {
+		return err
+	}
+	x, y := float64(size.Width)/2, float64(size.Height)/2
+	dx, dy := float64(size.Width)*0.3, float64(size.Height)*0.25
+	switch direction {
+	case gwda.DirectionUp:
+		return r.driver.SwipeFloat(x, y+dy, x, y-dy)
+	case gwda.DirectionDown:
+		return r.driver.SwipeFloat(x, y-dy, x, y+dy)
+	case gwda.DirectionLeft:
+		return r.driver.SwipeFloat(x+dx, y, x-dx, y)
+	case gwda.DirectionRight:
+		return r.driver.SwipeFloat(x-dx, y, x+dx, y)
+	}
+	return fmt.Errorf("unknown direction %q", direction)
+}
+
+// lookup Returns the element of the target, or nil if it does not exist.
+// The other errors, e.g. of a dead session, are returned rather than waited out.
+func (r *runner) lookup(target Target) (gwda.WebElement, error) {
+	elems, err := r.driver.FindElements(target.selector(r.expand))
+	if err != nil {
+		if isNoSuchElement(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find %s: %w", r.describeTarget(target), err)
+	}
+	if target.Index < 0 || target.Index >= len(elems) {
+		return nil, nil
+	}
+	return elems[target.Index], nil
+}
+
+// isNoSuchElement gwda and WDA report `no such element` when nothing matches
+func isNoSuchElement(err error) bool {
+	return strings.HasPrefix(err.Error(), "no such element")
+}
+
+// isNoSuchAlert WDA reports `no such alert` when no alert is present
+func isNoSuchAlert(err error) bool {
+	return strings.HasPrefix(err.Error(), "no such alert")
+}
+
+// find Waits for the element of the target
+func (r *runner) find(target Target, timeout time.Duration) (gwda.WebElement, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		elem, err := r.lookup(target)
+		if err != nil || elem != nil {
+			return elem, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("element not found within %v: %s", timeout, r.describeTarget(target))
+		}
+		time.Sleep(r.interval)
+	}
+}
+
+func (r *runner) describeTarget(target Target) string {
+	s := r.expand(target.String())
+	if target.Index != 0 {
+		s += fmt.Sprintf(" at index %d", target.Index)
+	}
+	return s
+}
+
+// check Evaluates the condition once, explaining why it does not hold
+func (r *runner) check(c *Condition) (ok bool, why string, err error) {
+	var elem gwda.WebElement
+	if c.Exists != nil {
+		if elem, err = r.lookup(*c.Exists); err != nil || elem == nil {
+			return false, "not found: " + r.describeTarget(*c.Exists), err
+		}
+	}
+	if c.Gone != nil {
+		if elem, err = r.lookup(*c.Gone); err != nil || elem != nil {
+			return false, "still exists: " + r.describeTarget(*c.Gone), err
+		}
+	}
+	if c.Visible != nil {
+		if elem, err = r.lookup(*c.Visible); err != nil {
+			return false, "", err
+		}
+		if elem == nil {
+			return false, "not found: " + r.describeTarget(*c.Visible), nil
+		}
+		if ok, err = elem.IsDisplayed(); err != nil || !ok {
+			return false, "not visible: " + r.describeTarget(*c.Visible), err
+		}
+	}
+	if c.Enabled != nil {
+		if elem, err = r.lookup(*c.Enabled); err != nil {
+			return false, "", err
+		}
+		if elem == nil {
+			return false, "not found: " + r.describeTarget(*c.Enabled), nil
+		}
+		if ok, err = elem.IsEnabled(); err != nil || !ok {
+			return false, "not enabled: " + r.describeTarget(*c.Enabled), err
+		}
+	}
+	if c.Text != nil {
+		if ok, why, err = r.checkText(c.Text); err != nil || !ok {
+			return false, why, err
+		}
+	}
+	if c.Alert != nil {
+		_, alertErr := r.driver.AlertText()
+		if alertErr != nil && !isNoSuchAlert(alertErr) {
+			return false, "", alertErr
+		}
+		if shown := alertErr == nil; shown != *c.Alert {
+			if shown {
+				return false, "an alert is shown", nil
+			}
+			return false, "no alert is shown", nil
+		}
+	}
+	if c.Equals != nil {
+		if len(c.Equals) != 2 {
+			return false, "", fmt.Errorf("equals needs two values, got %d", len(c.Equals))
+		}
+		if a, b := r.expand(c.Equals[0]), r.expand(c.Equals[1]); a != b {
+			return false, fmt.Sprintf("%q does not equal %q", a, b), nil
+		}
+	}
+	if c.Not != nil {
+		if ok, _, err = r.check(c.Not); err != nil {
+			return false, "", err
+		}
+		if ok {
+			return false, "negated condition holds", nil
+		}
+	}
+	return true, "", nil
+}
+
+func (r *runner) checkText(check *TextCheck) (ok bool, why string, err error) {
+	var elem gwda.WebElement
+	if elem, err = r.lookup(check.Target); err != nil {
+		return false, "", err
+	}
+	if elem == nil {
+		return false, "not found: " + r.describeTarget(check.Target), nil
+	}
+	var text string
+	if text, err = elem.Text(); err != nil {
+		return false, "", err
+	}
+	subject := "text of " + r.describeTarget(check.Target)
+	if check.Equals != nil {
+		if expected := r.expand(*check.Equals); text != expected {
+			return false, fmt.Sprintf("%s is %q, expected %q", subject, text, expected), nil
+		}
+	}
+	if check.Contains != "" {
+		if expected := r.expand(check.Contains); !strings.Contains(text, expected) {
+			return false, fmt.Sprintf("%s is %q, expected to contain %q", subject, text, expected), nil
+		}
+	}
+	if check.Matches != "" {
+		var re *regexp.Regexp
+		if re, err = regexp.Compile(r.expand(check.Matches)); err != nil {
+			return false, "", err
+		}
+		if !re.MatchString(text) {
+			return false, fmt.Sprintf("%s is %q, expected to match %q", subject, text, re.String()), nil
+		}
+	}
+	return true, "", nil
+}
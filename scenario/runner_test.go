@@ -0,0 +1,359 @@
+package scenario
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/electricbubble/gwda"
+)
+
+type fakeElement struct {
+	gwda.WebElement
+	wd   *fakeDriver
+	name string
+	text string
+}
+
+func (we *fakeElement) Click() error {
+	we.wd.log("click " + we.name)
+	return nil
+}
+
+func (we *fakeElement) Clear() error {
+	we.text = ""
+	return nil
+}
+
+func (we *fakeElement) SendKeys(text string, frequency ...int) error {
+	we.text += text
+	we.wd.log("type " + we.name + " " + text)
+	return nil
+}
+
+func (we *fakeElement) Text() (string, error) {
+	return we.text, nil
+}
+
+func (we *fakeElement) IsDisplayed() (bool, error) {
+	return true, nil
+}
+
+type fakeDriver struct {
+	gwda.WebDriver
+	elements map[string]*fakeElement
+	alert    string
+	calls    []string
+	// appear Adds the element once it has been looked up the given number of times
+	appear  map[string]int
+	lookups map[string]int
+	// findErr Fails the lookups, e.g. as a dead session
+	findErr error
+	// alertErr Fails the alert checks
+	alertErr error
+}
+
+func newFakeDriver(names ...string) *fakeDriver {
+	wd := &fakeDriver{elements: make(map[string]*fakeElement), appear: make(map[string]int), lookups: make(map[string]int)}
+	for _, name := range names {
+		wd.elements[name] = &fakeElement{wd: wd, name: name}
+	}
+	return wd
+}
+
+func (wd *fakeDriver) log(call string) {
+	wd.calls = append(wd.calls, call)
+}
+
+func (wd *fakeDriver) FindElements(by gwda.BySelector) ([]gwda.WebElement, error) {
+	if wd.findErr != nil {
+		return nil, wd.findErr
+	}
+	name := by.Name
+	wd.lookups[name]++
+	if n, ok := wd.appear[name]; ok && wd.lookups[name] >= n {
+		wd.elements[name] = &fakeElement{wd: wd, name: name, text: "hello " + name}
+	}
+	if elem, ok := wd.elements[name]; ok {
+		return []gwda.WebElement{elem}, nil
+	}
+	return nil, nil
+}
+
+func (wd *fakeDriver) AppLaunch(bundleId string, launchOpt ...gwda.AppLaunchOption) error {
+	wd.log("launch " + bundleId)
+	return nil
+}
+
+func (wd *fakeDriver) TapFloat(x, y float64) error {
+	wd.log(fmt.Sprintf("tap %v %v", x, y))
+	return nil
+}
+
+func (wd *fakeDriver) SendKeys(text string, frequency ...int) error {
+	wd.log("type " + text)
+	return nil
+}
+
+func (wd *fakeDriver) AlertText() (string, error) {
+	if wd.alertErr != nil {
+		return "", wd.alertErr
+	}
+	if wd.alert == "" {
+		return "", errors.New("no such alert: An attempt was made to operate on a modal dialog when one was not open")
+	}
+	return wd.alert, nil
+}
+
+func (wd *fakeDriver) AlertAccept(label ...string) error {
+	wd.log(fmt.Sprintf("accept %v", label))
+	wd.alert = ""
+	return nil
+}
+
+func (wd *fakeDriver) Screenshot() (*bytes.Buffer, error) {
+	// as with `screenshotQuality` set
+	buf := new(bytes.Buffer)
+	err := jpeg.Encode(buf, image.NewRGBA(image.Rect(0, 0, 4, 4)), nil)
+	return buf, err
+}
+
+func TestRun(t *testing.T) {
+	scenario, err := Parse([]byte(`
+name: Login flow
+vars: {user: alice}
+steps:
+  - launch: com.example.app
+  - alert: {accept: Allow}
+    if: {alert: true}
+  - alert: accept
+    if: {alert: true}
+    else:
+      - type: no alert
+  - type: {name: username, text: "${user}"}
+  - tap: {name: login}
+  - wait: {exists: {name: welcome}}
+  - store: {name: welcome, var: greeting}
+  - assert: {text: {name: welcome, equals: "hello welcome"}}
+  - foreach:
+      items: [a, b]
+      steps:
+        - type: "${item}${index}"
+          if: {equals: ["${item}", b]}
+  - set: {message: "${greeting}, ${user}"}
+  - type: "${message}"
+  - repeat:
+      until: {exists: {name: done}}
+      steps:
+        - tap: {x: 0, y: 0}
+  - assert: {gone: {name: welcome}}
+    optional: true
+  - screenshot: shots/${scenario}.png
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := os.MkdirTemp("", "scenario")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	wd := newFakeDriver("username", "login")
+	wd.alert = "Allow notifications?"
+	wd.appear["welcome"] = 3
+	wd.appear["done"] = 3
+	var logs []string
+	result := Run(wd, scenario, WithOutputDir(dir), WithPollInterval(time.Millisecond),
+		WithLogger(func(format string, args ...interface{}) { logs = append(logs, fmt.Sprintf(format, args...)) }))
+	if !result.Passed {
+		t.Fatalf("expected to pass: %s", result.Error)
+	}
+
+	expected := []string{
+		"launch com.example.app",
+		"accept [Allow]",
+		"type no alert",
+		"type username alice",
+		"click login",
+		"type b2",
+		"type hello welcome, alice",
+		"tap 0 0",
+		"tap 0 0",
+	}
+	if !reflect.DeepEqual(wd.calls, expected) {
+		t.Fatalf("expected %q, got %q", expected, wd.calls)
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(dir, "shots", "Login flow.png")); err != nil || !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Fatalf("expected a PNG file: %v", err)
+	}
+
+	var statuses []string
+	for _, step := range result.Steps {
+		statuses = append(statuses, step.Index+":"+string(step.Status))
+	}
+	expectedStatuses := []string{
+		"1:passed", "2:passed", "3:passed", "3.1:passed", "4:passed", "5:passed", "6:passed", "7:passed", "8:passed",
+		"9:passed", "9[1].1:skipped", "9[2].1:passed", "10:passed", "11:passed",
+		"12:passed", "12[1].1:passed", "12[2].1:passed", "13:skipped", "14:passed",
+	}
+	if !reflect.DeepEqual(statuses, expectedStatuses) {
+		t.Fatalf("expected %q, got %q", expectedStatuses, statuses)
+	}
+	if !strings.HasPrefix(result.Steps[17].Error, "optional step failed: assertion failed: still exists") {
+		t.Fatalf("unexpected error: %s", result.Steps[17].Error)
+	}
+	if len(logs) != len(result.Steps) {
+		t.Fatalf("expected a log per step, got %q", logs)
+	}
+}
+
+func TestRunFailure(t *testing.T) {
+	scenario, err := Parse([]byte(`
+name: Failure
+timeout: 10ms
+steps:
+  - steps:
+      - tap: {name: login}
+      - tap: {name: missing}
+  - launch: com.example.app
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := os.MkdirTemp("", "scenario")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	wd := newFakeDriver("login")
+	result := Run(wd, scenario, WithOutputDir(dir), WithPollInterval(time.Millisecond))
+	if result.Passed || result.Error != `step 1.2: element not found within 10ms: name "missing"` {
+		t.Fatalf("unexpected result: %v %s", result.Passed, result.Error)
+	}
+	if len(result.Steps) != 4 {
+		t.Fatalf("unexpected steps: %+v", result.Steps)
+	}
+	group, failed, notRun := result.Steps[0], result.Steps[2], result.Steps[3]
+	if group.Status != StatusFailed || group.Screenshot != "" {
+		t.Fatalf("unexpected group: %+v", group)
+	}
+	if failed.Status != StatusFailed || failed.Screenshot != filepath.Join(dir, "Failure-step-1.2.png") {
+		t.Fatalf("unexpected failed step: %+v", failed)
+	}
+	if notRun.Status != StatusSkipped || notRun.Error != "not run" {
+		t.Fatalf("unexpected skipped step: %+v", notRun)
+	}
+	if len(wd.calls) != 1 {
+		t.Fatalf("expected the scenario to stop, got %q", wd.calls)
+	}
+}
+
+func TestRunFindError(t *testing.T) {
+	scenario, err := Parse([]byte(`
+name: Find error
+timeout: 10ms
+steps:
+  - tap: {name: login}
+    timeout: 10s
+  - tap: {name: login}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := os.MkdirTemp("", "scenario")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// the lookups are not retried for 10s once the session is gone
+	wd := newFakeDriver("login")
+	wd.findErr = errors.New("invalid session id: Session does not exist")
+	start := time.Now()
+	result := Run(wd, scenario, WithOutputDir(dir), WithPollInterval(time.Millisecond))
+	if result.Passed || result.Error != `step 1: find name "login": invalid session id: Session does not exist` {
+		t.Fatalf("unexpected result: %v %s", result.Passed, result.Error)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the step to fail at once, took %v", elapsed)
+	}
+
+	// an element matching nothing is waited for
+	wd.findErr = errors.New("no such element: unable to find an element using 'link text', value 'name=login'")
+	scenario.Steps = scenario.Steps[1:]
+	result = Run(wd, scenario, WithOutputDir(dir), WithPollInterval(time.Millisecond))
+	if result.Passed || result.Error != `step 1: element not found within 10ms: name "login"` {
+		t.Fatalf("unexpected result: %v %s", result.Passed, result.Error)
+	}
+}
+
+func TestRunSet(t *testing.T) {
+	for _, parse := range []func([]byte) (*Scenario, error){Parse, ParseJSON} {
+		scenario, err := parse([]byte(`{
+	"name": "Set",
+	"steps": [
+		{"set": {"z": "1", "a": "${z}-x", "m": "${a}-y"}},
+		{"assert": {"equals": ["${m}", "1-x-y"]}}
+	]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := Run(newFakeDriver(), scenario, WithFailureScreenshots(false)); !result.Passed {
+			t.Fatalf("unexpected result: %s", result.Error)
+		}
+	}
+}
+
+func TestRunAlertError(t *testing.T) {
+	scenario, err := Parse([]byte(`
+name: Alert error
+steps:
+  - assert: {alert: false}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd := newFakeDriver()
+	if result := Run(wd, scenario, WithFailureScreenshots(false)); !result.Passed {
+		t.Fatalf("unexpected result: %s", result.Error)
+	}
+	// an error other than no such alert does not mean that no alert is shown
+	wd.alertErr = errors.New("invalid session id: Session does not exist")
+	if result := Run(wd, scenario, WithFailureScreenshots(false)); result.Passed || result.Error != "step 1: invalid session id: Session does not exist" {
+		t.Fatalf("unexpected result: %v %s", result.Passed, result.Error)
+	}
+}
+
+func TestRunNestedLoops(t *testing.T) {
+	scenario, err := Parse([]byte(`
+name: Nested
+vars: {log: ""}
+steps:
+  - foreach:
+      items: [a, b]
+      steps:
+        - repeat:
+            times: 3
+            steps:
+              - set: {log: "${log}${index}"}
+        - set: {log: "${log}${item}${index} "}
+  - assert: {equals: ["${log}", "123a1 123b2 "]}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := Run(newFakeDriver(), scenario, WithFailureScreenshots(false)); !result.Passed {
+		t.Fatalf("unexpected result: %s", result.Error)
+	}
+}
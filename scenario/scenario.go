@@ -0,0 +1,586 @@
+// Package scenario runs test flows declared in YAML or JSON against a gwda.WebDriver,
+// so that flows can be written without Go code.
+//
+//  name: Login
+//  vars:
+//    user: alice
+//  timeout: 10s
+//  steps:
+//    - launch: com.example.app
+//    - alert: accept
+//      if: {alert: true}
+//    - type: {name: username, text: "${user}"}
+//    - tap: {predicate: "label == 'Login'"}
+//    - wait: {visible: {name: welcome}}
+//      timeout: 20s
+//    - assert: {text: {name: welcome, contains: "${user}"}}
+//    - screenshot: "${scenario}-done.png"
+package scenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/electricbubble/gwda"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario A named list of steps.
+//  Vars: the initial variables, referenced as `${name}` in any string of the steps
+//  Timeout: the default timeout of the steps, `10s` if empty
+type Scenario struct {
+	Name    string            `yaml:"name"`
+	Vars    map[string]string `yaml:"vars"`
+	Timeout Duration          `yaml:"timeout"`
+	Steps   []Step            `yaml:"steps"`
+}
+
+// Step Runs exactly one action, or the nested steps as a group.
+//  Name: shown in the report, the action by default
+//  Timeout: how long to wait for elements and conditions
+//  If: skips the step, or runs Else, unless the condition holds
+//  Optional: a failure is reported but does not fail the scenario
+type Step struct {
+	Name     string     `yaml:"name"`
+	Timeout  Duration   `yaml:"timeout"`
+	If       *Condition `yaml:"if"`
+	Else     []Step     `yaml:"else"`
+	Optional bool       `yaml:"optional"`
+
+	// Launch Launches the application with the bundle id
+	Launch string `yaml:"launch"`
+	// Terminate Terminates the application with the bundle id
+	Terminate string `yaml:"terminate"`
+	// Tap Clicks the element, or taps the coordinate
+	Tap *Target `yaml:"tap"`
+	// Type Types into the element, or into the focused element
+	Type *TypeAction `yaml:"type"`
+	// Swipe Swipes the screen or the element
+	Swipe *SwipeAction `yaml:"swipe"`
+	// Press Presses a hardware button, `home`, `volumeUp` or `volumeDown`
+	Press string `yaml:"press"`
+	// Alert Accepts or dismisses the alert, or types into it
+	Alert *AlertAction `yaml:"alert"`
+	// Wait Waits until the condition holds
+	Wait *Condition `yaml:"wait"`
+	// Assert Fails unless the condition holds, without waiting
+	Assert *Condition `yaml:"assert"`
+	// Screenshot Saves a screenshot as PNG
+	Screenshot string `yaml:"screenshot"`
+	// Sleep Pauses for the duration
+	Sleep Duration `yaml:"sleep"`
+	// Set Assigns variables, in the order they are written
+	Set Assignments `yaml:"set"`
+	// Store Assigns the text of an element to a variable
+	Store *StoreAction `yaml:"store"`
+	// Repeat Runs the steps several times, or while a condition holds
+	Repeat *RepeatAction `yaml:"repeat"`
+	// ForEach Runs the steps for each item
+	ForEach *ForEachAction `yaml:"foreach"`
+	// Steps Runs the steps as a group, e.g. under a condition
+	Steps []Step `yaml:"steps"`
+}
+
+// Target An element, found by one of the selectors, or a coordinate in points.
+//  Index: the 0-based index among the matching elements
+type Target struct {
+	Predicate       string   `yaml:"predicate"`
+	ClassChain      string   `yaml:"classChain"`
+	XPath           string   `yaml:"xpath"`
+	Name            string   `yaml:"name"`
+	ID              string   `yaml:"id"`
+	AccessibilityID string   `yaml:"accessibilityId"`
+	Label           string   `yaml:"label"`
+	Index           int      `yaml:"index"`
+	X               *float64 `yaml:"x"`
+	Y               *float64 `yaml:"y"`
+}
+
+// isPoint Whether the target is a coordinate instead of an element
+func (t Target) isPoint() bool {
+	return t.X != nil || t.Y != nil
+}
+
+func (t Target) isEmpty() bool {
+	return !t.isPoint() && t.Predicate == "" && t.ClassChain == "" && t.XPath == "" &&
+		t.Name == "" && t.ID == "" && t.AccessibilityID == "" && t.Label == ""
+}
+
+func (t Target) String() string {
+	switch {
+	case t.isPoint():
+		return fmt.Sprintf("(%v, %v)", deref(t.X), deref(t.Y))
+	case t.Predicate != "":
+		return "predicate " + strconv.Quote(t.Predicate)
+	case t.ClassChain != "":
+		return "class chain " + strconv.Quote(t.ClassChain)
+	case t.XPath != "":
+		return "xpath " + strconv.Quote(t.XPath)
+	case t.Name != "":
+		return "name " + strconv.Quote(t.Name)
+	case t.ID != "":
+		return "id " + strconv.Quote(t.ID)
+	case t.AccessibilityID != "":
+		return "accessibility id " + strconv.Quote(t.AccessibilityID)
+	default:
+		return "label " + strconv.Quote(t.Label)
+	}
+}
+
+func deref(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
+
+// selector Converts the target, with the variables substituted, into a gwda.BySelector
+func (t Target) selector(expand func(string) string) gwda.BySelector {
+	switch {
+	case t.Predicate != "":
+		return gwda.BySelector{Predicate: expand(t.Predicate)}
+	case t.ClassChain != "":
+		return gwda.BySelector{ClassChain: expand(t.ClassChain)}
+	case t.XPath != "":
+		return gwda.BySelector{XPath: expand(t.XPath)}
+	case t.Name != "":
+		return gwda.BySelector{Name: expand(t.Name)}
+	case t.ID != "":
+		return gwda.BySelector{Id: expand(t.ID)}
+	case t.AccessibilityID != "":
+		return gwda.BySelector{AccessibilityId: expand(t.AccessibilityID)}
+	default:
+		return gwda.BySelector{LinkText: gwda.NewElementAttribute().WithLabel(expand(t.Label))}
+	}
+}
+
+// TypeAction Types the text, into the focused element if the target is empty.
+// A plain string is typed into the focused element.
+//  Clear: clears the element first
+type TypeAction struct {
+	Target `yaml:",inline"`
+	Text   string `yaml:"text"`
+	Clear  bool   `yaml:"clear"`
+}
+
+func (a *TypeAction) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		a.Text = value.Value
+		return nil
+	}
+	type plain TypeAction
+	return value.Decode((*plain)(a))
+}
+
+// SwipeAction Swipes in a direction, over the element if the target is not empty,
+// or between two coordinates. A plain string is a direction over the whole screen.
+type SwipeAction struct {
+	Target    `yaml:",inline"`
+	Direction string    `yaml:"direction"`
+	From      []float64 `yaml:"from"`
+	To        []float64 `yaml:"to"`
+}
+
+func (a *SwipeAction) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		a.Direction = value.Value
+		return nil
+	}
+	type plain SwipeAction
+	return value.Decode((*plain)(a))
+}
+
+// AlertAction `accept` or `dismiss` as a plain string, or with the label of the button to tap.
+//  SendKeys: types into the text field of the alert
+type AlertAction struct {
+	Accept   *string `yaml:"accept"`
+	Dismiss  *string `yaml:"dismiss"`
+	SendKeys string  `yaml:"sendKeys"`
+}
+
+func (a *AlertAction) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		empty := ""
+		switch value.Value {
+		case "accept":
+			a.Accept = &empty
+		case "dismiss":
+			a.Dismiss = &empty
+		default:
+			return fmt.Errorf("line %d: unknown alert action %q, expected accept or dismiss", value.Line, value.Value)
+		}
+		return nil
+	}
+	type plain AlertAction
+	return value.Decode((*plain)(a))
+}
+
+// Assignments Variables assigned in the order they are written, so that a value may refer to a variable set before
+type Assignments []Assignment
+
+type Assignment struct {
+	Name  string
+	Value string
+}
+
+func (a *Assignments) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: set needs a mapping of variables to values", value.Line)
+	}
+	// not nil, even without variables
+	*a = make(Assignments, 0, len(value.Content)/2)
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		var v string
+		if err := value.Content[i+1].Decode(&v); err != nil {
+			return err
+		}
+		*a = append(*a, Assignment{Name: value.Content[i].Value, Value: v})
+	}
+	return nil
+}
+
+func (a Assignments) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, assignment := range a {
+		node.Content = append(node.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: assignment.Name},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: assignment.Value})
+	}
+	return node, nil
+}
+
+// StoreAction Assigns the text of the element, or one of its attributes, to the variable
+type StoreAction struct {
+	Target    `yaml:",inline"`
+	Var       string `yaml:"var"`
+	Attribute string `yaml:"attribute"`
+}
+
+// RepeatAction Runs the steps the number of times, or while or until the condition holds.
+// With a condition, Times is the maximum number of iterations, 100 by default.
+// The 1-based iteration is available as `${index}`.
+type RepeatAction struct {
+	Times int        `yaml:"times"`
+	While *Condition `yaml:"while"`
+	Until *Condition `yaml:"until"`
+	Steps []Step     `yaml:"steps"`
+}
+
+// ForEachAction Runs the steps for each item, which is available as the variable, `${item}` by default
+type ForEachAction struct {
+	Var   string   `yaml:"var"`
+	Items []string `yaml:"items"`
+	Steps []Step   `yaml:"steps"`
+}
+
+// Condition Holds if all of its checks hold
+type Condition struct {
+	// Exists The element exists
+	Exists *Target `yaml:"exists"`
+	// Gone The element does not exist
+	Gone *Target `yaml:"gone"`
+	// Visible The element exists and is displayed
+	Visible *Target `yaml:"visible"`
+	// Enabled The element exists and is enabled
+	Enabled *Target `yaml:"enabled"`
+	// Text The text of the element matches
+	Text *TextCheck `yaml:"text"`
+	// Alert Whether an alert is shown
+	Alert *bool `yaml:"alert"`
+	// Equals The two strings are equal after substituting the variables
+	Equals []string `yaml:"equals"`
+	// Not The condition does not hold
+	Not *Condition `yaml:"not"`
+}
+
+// isEmpty Whether the condition has no check, or only negates an empty condition
+func (c *Condition) isEmpty() bool {
+	return c.Exists == nil && c.Gone == nil && c.Visible == nil && c.Enabled == nil &&
+		c.Text == nil && c.Alert == nil && c.Equals == nil && (c.Not == nil || c.Not.isEmpty())
+}
+
+// TextCheck Compares the text of the element
+type TextCheck struct {
+	Target   `yaml:",inline"`
+	Equals   *string `yaml:"equals"`
+	Contains string  `yaml:"contains"`
+	Matches  string  `yaml:"matches"`
+}
+
+// Duration A `time.Duration` written as `1.5s` or as a number of seconds
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	if seconds, err := strconv.ParseFloat(value.Value, 64); err == nil {
+		*d = Duration(seconds * float64(time.Second))
+		return nil
+	}
+	duration, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid duration %q", value.Line, value.Value)
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+// Load Reads a scenario from a `.json`, `.yaml` or `.yml` file
+func Load(path string) (scenario *Scenario, err error) {
+	var data []byte
+	if data, err = ioutil.ReadFile(path); err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		scenario, err = ParseJSON(data)
+	} else {
+		scenario, err = Parse(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if scenario.Name == "" {
+		scenario.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return scenario, nil
+}
+
+// Parse Parses a scenario in YAML
+func Parse(data []byte) (scenario *Scenario, err error) {
+	scenario = new(Scenario)
+	if err = yaml.Unmarshal(data, scenario); err != nil {
+		return nil, fmt.Errorf("parse scenario: %w", err)
+	}
+	if err = scenario.Validate(); err != nil {
+		return nil, err
+	}
+	return scenario, nil
+}
+
+// ParseJSON Parses a scenario in JSON, which has the same fields as YAML
+func ParseJSON(data []byte) (scenario *Scenario, err error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var node *yaml.Node
+	if node, err = jsonNode(dec); err != nil {
+		return nil, fmt.Errorf("parse scenario: %w", err)
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		return nil, errors.New("parse scenario: invalid data after the top-level value")
+	}
+	// the YAML decoders handle the plain strings and durations
+	if data, err = yaml.Marshal(node); err != nil {
+		return nil, fmt.Errorf("parse scenario: %w", err)
+	}
+	return Parse(data)
+}
+
+// jsonNode Reads the next JSON value as a YAML node, which keeps the order of the keys, e.g. of `set`
+func jsonNode(dec *json.Decoder) (*yaml.Node, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		if token == '{' {
+			node.Kind = yaml.MappingNode
+		}
+		for dec.More() {
+			if node.Kind == yaml.MappingNode {
+				var key json.Token
+				if key, err = dec.Token(); err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			var child *yaml.Node
+			if child, err = jsonNode(dec); err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		// the closing delimiter
+		if _, err = dec.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: token}, nil
+	case json.Number:
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: token.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(token)}, nil
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+}
+
+// Validate Checks that each step has exactly one action
+func (s *Scenario) Validate() error {
+	if len(s.Steps) == 0 {
+		return errors.New("scenario has no steps")
+	}
+	return validateSteps(s.Steps, "")
+}
+
+func validateSteps(steps []Step, prefix string) error {
+	for i := range steps {
+		index := prefix + strconv.Itoa(i+1)
+		step := &steps[i]
+		actions := step.actions()
+		if len(actions) != 1 {
+			if len(actions) == 0 {
+				return fmt.Errorf("step %s: no action", index)
+			}
+			return fmt.Errorf("step %s: several actions: %s", index, strings.Join(actions, ", "))
+		}
+		if len(step.Else) != 0 && step.If == nil {
+			return fmt.Errorf("step %s: else without if", index)
+		}
+		var err error
+		switch {
+		case step.Tap != nil && step.Tap.isEmpty(),
+			step.Store != nil && (step.Store.isEmpty() || step.Store.Var == ""):
+			err = errors.New("missing element")
+		case step.Swipe != nil && step.Swipe.Direction == "" && (len(step.Swipe.From) != 2 || len(step.Swipe.To) != 2):
+			err = errors.New("swipe needs a direction, or from and to as [x, y]")
+		case step.Alert != nil && step.Alert.Accept == nil && step.Alert.Dismiss == nil && step.Alert.SendKeys == "":
+			err = errors.New("alert needs accept, dismiss or sendKeys")
+		case step.Alert != nil && step.Alert.Accept != nil && step.Alert.Dismiss != nil:
+			err = errors.New("alert cannot both accept and dismiss")
+		case step.Repeat != nil && step.Repeat.Times <= 0 && step.Repeat.While == nil && step.Repeat.Until == nil:
+			err = errors.New("repeat needs times, while or until")
+		}
+		if err == nil {
+			err = step.validateConditions()
+		}
+		if err != nil {
+			return fmt.Errorf("step %s: %w", index, err)
+		}
+		for _, nested := range [][]Step{step.Steps, step.Else, step.repeatSteps(), step.forEachSteps()} {
+			if len(nested) != 0 {
+				if err = validateSteps(nested, index+"."); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validateConditions An empty condition would always hold
+func (step *Step) validateConditions() error {
+	names := []string{"if", "wait", "assert", "while", "until"}
+	conditions := []*Condition{step.If, step.Wait, step.Assert, nil, nil}
+	if step.Repeat != nil {
+		conditions[3], conditions[4] = step.Repeat.While, step.Repeat.Until
+	}
+	for i, c := range conditions {
+		if c != nil && c.isEmpty() {
+			return fmt.Errorf("empty %s condition", names[i])
+		}
+	}
+	return nil
+}
+
+func (step *Step) repeatSteps() []Step {
+	if step.Repeat == nil {
+		return nil
+	}
+	return step.Repeat.Steps
+}
+
+func (step *Step) forEachSteps() []Step {
+	if step.ForEach == nil {
+		return nil
+	}
+	return step.ForEach.Steps
+}
+
+// actions Returns the names of the actions set on the step
+func (step *Step) actions() (actions []string) {
+	for _, action := range []struct {
+		name string
+		set  bool
+	}{
+		{"launch", step.Launch != ""},
+		{"terminate", step.Terminate != ""},
+		{"tap", step.Tap != nil},
+		{"type", step.Type != nil},
+		{"swipe", step.Swipe != nil},
+		{"press", step.Press != ""},
+		{"alert", step.Alert != nil},
+		{"wait", step.Wait != nil},
+		{"assert", step.Assert != nil},
+		{"screenshot", step.Screenshot != ""},
+		{"sleep", step.Sleep != 0},
+		{"set", step.Set != nil},
+		{"store", step.Store != nil},
+		{"repeat", step.Repeat != nil},
+		{"foreach", step.ForEach != nil},
+		{"steps", step.Steps != nil},
+	} {
+		if action.set {
+			actions = append(actions, action.name)
+		}
+	}
+	return
+}
+
+// describe Summarizes the action for the report, e.g. `tap name "login"`
+func (step *Step) describe() string {
+	switch {
+	case step.Launch != "":
+		return "launch " + step.Launch
+	case step.Terminate != "":
+		return "terminate " + step.Terminate
+	case step.Tap != nil:
+		return "tap " + step.Tap.String()
+	case step.Type != nil:
+		if step.Type.Target.isEmpty() {
+			return "type " + strconv.Quote(step.Type.Text)
+		}
+		return fmt.Sprintf("type %q into %s", step.Type.Text, step.Type.Target)
+	case step.Swipe != nil:
+		if step.Swipe.Direction == "" {
+			return fmt.Sprintf("swipe from %v to %v", step.Swipe.From, step.Swipe.To)
+		}
+		if step.Swipe.Target.isEmpty() {
+			return "swipe " + step.Swipe.Direction
+		}
+		return fmt.Sprintf("swipe %s on %s", step.Swipe.Direction, step.Swipe.Target)
+	case step.Press != "":
+		return "press " + step.Press
+	case step.Alert != nil:
+		switch {
+		case step.Alert.Accept != nil:
+			return strings.TrimSpace("alert accept " + *step.Alert.Accept)
+		case step.Alert.Dismiss != nil:
+			return strings.TrimSpace("alert dismiss " + *step.Alert.Dismiss)
+		}
+		return "alert send keys"
+	case step.Screenshot != "":
+		return "screenshot " + step.Screenshot
+	case step.Sleep != 0:
+		return "sleep " + time.Duration(step.Sleep).String()
+	case step.Store != nil:
+		return fmt.Sprintf("store %s of %s", step.Store.Var, step.Store.Target)
+	case step.Repeat != nil && step.Repeat.While == nil && step.Repeat.Until == nil:
+		return fmt.Sprintf("repeat %d times", step.Repeat.Times)
+	case step.ForEach != nil:
+		return fmt.Sprintf("foreach of %d items", len(step.ForEach.Items))
+	}
+	return step.actions()[0]
+}
@@ -0,0 +1,103 @@
+package scenario
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const loginYAML = `
+name: Login
+vars:
+  user: alice
+timeout: 2s
+steps:
+  - launch: com.example.app
+  - alert: accept
+    if: {alert: true}
+  - type: {name: username, text: "${user}", clear: true}
+  - type: secret
+  - tap: {predicate: "label == 'Login'"}
+  - swipe: up
+  - wait: {visible: {name: welcome}}
+    timeout: 1.5
+  - repeat:
+      times: 2
+      steps:
+        - tap: {x: 10, y: 20}
+  - assert: {text: {name: welcome, contains: "${user}"}}
+`
+
+func TestParse(t *testing.T) {
+	scenario, err := Parse([]byte(loginYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scenario.Name != "Login" || scenario.Vars["user"] != "alice" || time.Duration(scenario.Timeout) != 2*time.Second || len(scenario.Steps) != 9 {
+		t.Fatalf("unexpected scenario: %+v", scenario)
+	}
+	steps := scenario.Steps
+	if steps[1].Alert.Accept == nil || *steps[1].If.Alert != true {
+		t.Fatalf("unexpected alert step: %+v", steps[1])
+	}
+	if steps[2].Type.Name != "username" || steps[2].Type.Text != "${user}" || !steps[2].Type.Clear {
+		t.Fatalf("unexpected type step: %+v", steps[2].Type)
+	}
+	if !steps[3].Type.isEmpty() || steps[3].Type.Text != "secret" {
+		t.Fatalf("unexpected type step: %+v", steps[3].Type)
+	}
+	if steps[5].Swipe.Direction != "up" || time.Duration(steps[6].Timeout) != 1500*time.Millisecond {
+		t.Fatalf("unexpected steps: %+v %+v", steps[5].Swipe, steps[6])
+	}
+	if tap := steps[7].Repeat.Steps[0].Tap; *tap.X != 10 || *tap.Y != 20 {
+		t.Fatalf("unexpected tap: %+v", tap)
+	}
+	if got := steps[8].Assert.Text.Contains; got != "${user}" {
+		t.Fatalf("unexpected assert: %q", got)
+	}
+	if got := steps[4].describe(); got != `tap predicate "label == 'Login'"` {
+		t.Fatalf("unexpected description: %s", got)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	scenario, err := ParseJSON([]byte(`{
+	"name": "JSON",
+	"steps": [
+		{"launch": "com.example.app", "timeout": "3s"},
+		{"alert": {"dismiss": "Not Now"}, "optional": true},
+		{"foreach": {"items": ["a", "b"], "steps": [{"type": "${item}"}]}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := scenario.Steps
+	if time.Duration(steps[0].Timeout) != 3*time.Second || *steps[1].Alert.Dismiss != "Not Now" || !steps[1].Optional {
+		t.Fatalf("unexpected steps: %+v", steps)
+	}
+	if steps[2].ForEach.Steps[0].Type.Text != "${item}" {
+		t.Fatalf("unexpected foreach: %+v", steps[2].ForEach)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for source, expected := range map[string]string{
+		`steps: []`:                                   "no steps",
+		`steps: [{name: x}]`:                          "step 1: no action",
+		`steps: [{launch: a, terminate: b}]`:          "step 1: several actions: launch, terminate",
+		`steps: [{steps: [{tap: {}}]}]`:               "step 1.1: missing element",
+		`steps: [{swipe: {from: [1, 2]}}]`:            "step 1: swipe needs",
+		`steps: [{launch: a, else: [{launch: b}]}]`:   "step 1: else without if",
+		`steps: [{alert: maybe}]`:                     "unknown alert action",
+		`steps: [{sleep: soon}]`:                      "invalid duration",
+		`steps: [{wait: {}}]`:                         "step 1: empty wait condition",
+		`steps: [{steps: [{assert: {not: {}}}]}]`:     "step 1.1: empty assert condition",
+		`steps: [{repeat: {until: {}, steps: []}}]`:   "step 1: empty until condition",
+		`steps: [{alert: {accept: OK, dismiss: ""}}]`: "step 1: alert cannot both accept and dismiss",
+	} {
+		_, err := Parse([]byte(source))
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected %q, got %v", source, expected, err)
+		}
+	}
+}
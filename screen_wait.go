@@ -0,0 +1,254 @@
+package gwda
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"time"
+
+	"github.com/electricbubble/gwda/imgdiff"
+)
+
+// ScreenDiffMetric Measures the difference of two frames of the same size, from 0 (equal) to 1
+type ScreenDiffMetric func(a, b image.Image) float64
+
+// ScreenDiffPixelRatio The ratio of pixels whose perceived color differs by more than the tolerance,
+// see `imgdiff.WithTolerance`
+func ScreenDiffPixelRatio(tolerance float64) ScreenDiffMetric {
+	return func(a, b image.Image) float64 {
+		result, err := imgdiff.Compare(a, b, imgdiff.WithTolerance(tolerance))
+		if err != nil {
+			return 1
+		}
+		return result.DiffRatio
+	}
+}
+
+// ScreenDiffMeanLuminance The mean absolute difference of the luminance
+func ScreenDiffMeanLuminance(a, b image.Image) float64 {
+	ga, gb := newGrayImage(a, a.Bounds()), newGrayImage(b, b.Bounds())
+	if ga.w != gb.w || ga.h != gb.h {
+		return 1
+	}
+	sum := 0.0
+	for i := range ga.pix {
+		sum += math.Abs(ga.pix[i] - gb.pix[i])
+	}
+	return sum / float64(len(ga.pix)) / 255
+}
+
+// ScreenWaitOption Configure the behavior of WaitForScreenStable and WaitForScreenChange
+type ScreenWaitOption func(sw *screenWait)
+
+// WithScreenWaitTimeout Defaults to `DefaultWaitTimeout`
+func WithScreenWaitTimeout(timeout time.Duration) ScreenWaitOption {
+	return func(sw *screenWait) {
+		sw.timeout = timeout
+	}
+}
+
+// WithScreenWaitInterval The interval between screenshots, or the longest wait for an MJPEG frame.
+//  Defaults to `200ms`
+func WithScreenWaitInterval(interval time.Duration) ScreenWaitOption {
+	return func(sw *screenWait) {
+		sw.interval = interval
+	}
+}
+
+// WithScreenWaitMetric Defaults to `ScreenDiffPixelRatio(0.1)`
+func WithScreenWaitMetric(metric ScreenDiffMetric) ScreenWaitOption {
+	return func(sw *screenWait) {
+		sw.metric = metric
+	}
+}
+
+// WithScreenWaitRegion Compares only a region of the screen, in points
+func WithScreenWaitRegion(region Rect) ScreenWaitOption {
+	return func(sw *screenWait) {
+		sw.region = region
+	}
+}
+
+// WithScreenWaitThreshold The difference above which WaitForScreenChange reports a change.
+//  Defaults to `0.005`
+func WithScreenWaitThreshold(threshold float64) ScreenWaitOption {
+	return func(sw *screenWait) {
+		sw.threshold = threshold
+	}
+}
+
+// WithScreenWaitMjpeg Reads the frames from the MJPEG stream instead of taking screenshots,
+// which is faster and notices shorter changes.
+func WithScreenWaitMjpeg() ScreenWaitOption {
+	return func(sw *screenWait) {
+		sw.mjpeg = true
+	}
+}
+
+type screenWait struct {
+	driver    WebDriver
+	timeout   time.Duration
+	interval  time.Duration
+	metric    ScreenDiffMetric
+	region    Rect
+	threshold float64
+	mjpeg     bool
+
+	stream      *MjpegStream
+	windowSize  Size
+	orientation Orientation
+	last        image.Image
+	taken       bool
+}
+
+func newScreenWait(driver WebDriver, opts ...ScreenWaitOption) *screenWait {
+	sw := &screenWait{
+		driver:    driver,
+		timeout:   DefaultWaitTimeout,
+		interval:  200 * time.Millisecond,
+		metric:    ScreenDiffPixelRatio(0.1),
+		threshold: 0.005,
+	}
+	for _, opt := range opts {
+		opt(sw)
+	}
+	return sw
+}
+
+func (sw *screenWait) open() (err error) {
+	if !sw.mjpeg {
+		return nil
+	}
+	if sw.windowSize, err = sw.driver.WindowSize(); err != nil {
+		return err
+	}
+	if sw.orientation, err = sw.driver.Orientation(); err != nil {
+		return err
+	}
+	sw.stream, err = NewMjpegStream(sw.driver, WithMjpegStreamBuffer(1))
+	return
+}
+
+func (sw *screenWait) close() {
+	if sw.stream != nil {
+		_ = sw.stream.Close()
+	}
+}
+
+// next Returns the next frame, cropped to the region
+func (sw *screenWait) next() (img image.Image, err error) {
+	var screenshot DecodedScreenshot
+	if sw.stream == nil {
+		if sw.taken {
+			time.Sleep(sw.interval)
+		}
+		if screenshot, err = sw.driver.ScreenshotImage(); err != nil {
+			return nil, err
+		}
+	} else {
+		wait := sw.interval
+		if sw.last == nil {
+			wait = sw.timeout
+		}
+		select {
+		case frame, ok := <-sw.stream.Frames():
+			if !ok {
+				if err = sw.stream.Err(); err == nil {
+					err = fmt.Errorf("mjpeg stream closed")
+				}
+				return nil, err
+			}
+			screenshot = sw.frameScreenshot(frame.Image)
+		case <-time.After(wait):
+			// WDA only sends a frame once the screen changes
+			if sw.last == nil {
+				return nil, fmt.Errorf("no mjpeg frame within %v", wait)
+			}
+			return sw.last, nil
+		}
+	}
+	sw.taken = true
+	img = screenshot.Image
+	if sw.region != (Rect{}) {
+		area := screenshot.PointRectToPixel(sw.region)
+		cropped := image.NewRGBA(image.Rect(0, 0, area.Dx(), area.Dy()))
+		draw.Draw(cropped, cropped.Bounds(), img, area.Min, draw.Src)
+		img = cropped
+	}
+	sw.last = img
+	return img, nil
+}
+
+// frameScreenshot Uprights the MJPEG frame as `WebDriver.ScreenshotImage` does, the frames being in portrait
+func (sw *screenWait) frameScreenshot(frame image.Image) (screenshot DecodedScreenshot) {
+	screenshot = DecodedScreenshot{PointSize: sw.windowSize, Orientation: sw.orientation}
+	screenshot.Image = uprightScreenshot(frame, sw.orientation, sw.windowSize)
+	bounds := screenshot.Image.Bounds()
+	screenshot.PixelSize = Size{Width: bounds.Dx(), Height: bounds.Dy()}
+	return
+}
+
+func (sw *screenWait) diff(a, b image.Image) float64 {
+	if a.Bounds().Dx() != b.Bounds().Dx() || a.Bounds().Dy() != b.Bounds().Dy() {
+		return 1
+	}
+	return sw.metric(a, b)
+}
+
+// WaitForScreenStable Waits until consecutive frames differ by no more than the threshold for the duration,
+// e.g. once the animations after a navigation have finished.
+func WaitForScreenStable(driver WebDriver, threshold float64, duration time.Duration, opts ...ScreenWaitOption) (err error) {
+	sw := newScreenWait(driver, opts...)
+	if err = sw.open(); err != nil {
+		return err
+	}
+	defer sw.close()
+
+	startTime := time.Now()
+	var prev, cur image.Image
+	if prev, err = sw.next(); err != nil {
+		return err
+	}
+	stableSince := time.Now()
+	for time.Since(stableSince) < duration {
+		if elapsed := time.Since(startTime); elapsed > sw.timeout {
+			return fmt.Errorf("screen not stable: timeout after %v", elapsed)
+		}
+		if cur, err = sw.next(); err != nil {
+			return err
+		}
+		if sw.diff(prev, cur) > threshold {
+			stableSince = time.Now()
+		}
+		prev = cur
+	}
+	return nil
+}
+
+// WaitForScreenChange Waits until the region of the screen, in points, differs from its content at the time of the call.
+// The whole screen is compared if the region is empty.
+func WaitForScreenChange(driver WebDriver, region Rect, opts ...ScreenWaitOption) (err error) {
+	sw := newScreenWait(driver, append(opts, WithScreenWaitRegion(region))...)
+	if err = sw.open(); err != nil {
+		return err
+	}
+	defer sw.close()
+
+	startTime := time.Now()
+	var reference, cur image.Image
+	if reference, err = sw.next(); err != nil {
+		return err
+	}
+	for {
+		if elapsed := time.Since(startTime); elapsed > sw.timeout {
+			return fmt.Errorf("screen not changed: timeout after %v", elapsed)
+		}
+		if cur, err = sw.next(); err != nil {
+			return err
+		}
+		if sw.diff(reference, cur) > sw.threshold {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package gwda
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+	"time"
+)
+
+// fakeAnimatedDriver Moves a square across the screen for the first frames, then stands still
+type fakeAnimatedDriver struct {
+	WebDriver
+	frames   int
+	animated int
+}
+
+func (wd *fakeAnimatedDriver) ScreenshotImage() (DecodedScreenshot, error) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 200))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	step := wd.frames
+	if step > wd.animated {
+		step = wd.animated
+	}
+	draw.Draw(img, image.Rect(step*10, 150, step*10+10, 160), image.NewUniform(color.Black), image.Point{}, draw.Src)
+	wd.frames++
+	return DecodedScreenshot{Image: img, PixelSize: Size{Width: 100, Height: 200}, PointSize: Size{Width: 50, Height: 100}, Scale: 2}, nil
+}
+
+func TestWaitForScreenStable(t *testing.T) {
+	wd := &fakeAnimatedDriver{animated: 5}
+	err := WaitForScreenStable(wd, 0, 30*time.Millisecond, WithScreenWaitInterval(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wd.frames < 7 {
+		t.Fatalf("expected to wait for the animation, took %d frames", wd.frames)
+	}
+
+	wd = &fakeAnimatedDriver{animated: 1000}
+	err = WaitForScreenStable(wd, 0, 30*time.Millisecond, WithScreenWaitInterval(time.Millisecond), WithScreenWaitTimeout(50*time.Millisecond))
+	if err == nil {
+		t.Fatal("expected a timeout")
+	}
+	// the animation is outside the region
+	wd = &fakeAnimatedDriver{animated: 1000}
+	err = WaitForScreenStable(wd, 0, 30*time.Millisecond, WithScreenWaitInterval(time.Millisecond), WithScreenWaitTimeout(time.Second),
+		WithScreenWaitRegion(Rect{Size: Size{Width: 50, Height: 50}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitForScreenChange(t *testing.T) {
+	wd := &fakeAnimatedDriver{animated: 3}
+	if err := WaitForScreenChange(wd, Rect{Point: Point{Y: 70}, Size: Size{Width: 50, Height: 10}}, WithScreenWaitInterval(time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if wd.frames != 2 {
+		t.Fatalf("expected a change in the second frame, took %d frames", wd.frames)
+	}
+
+	wd = &fakeAnimatedDriver{animated: 3}
+	err := WaitForScreenChange(wd, Rect{Size: Size{Width: 50, Height: 50}}, WithScreenWaitInterval(time.Millisecond), WithScreenWaitTimeout(20*time.Millisecond),
+		WithScreenWaitMetric(ScreenDiffMeanLuminance))
+	if err == nil {
+		t.Fatal("expected a timeout")
+	}
+}
+
+func TestScreenWait_frameScreenshot(t *testing.T) {
+	// the frame of a landscape interface is in portrait, the region is at the top left of the window
+	frame := image.NewRGBA(image.Rect(0, 0, 100, 200))
+	draw.Draw(frame, frame.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(frame, image.Rect(0, 180, 10, 200), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	sw := newScreenWait(nil, WithScreenWaitRegion(Rect{Size: Size{Width: 10, Height: 5}}))
+	sw.windowSize, sw.orientation = Size{Width: 100, Height: 50}, OrientationLandscapeRight
+	screenshot := sw.frameScreenshot(frame)
+	if screenshot.PixelSize != (Size{Width: 200, Height: 100}) {
+		t.Fatalf("expected an upright frame, got %+v", screenshot.PixelSize)
+	}
+	area := screenshot.PointRectToPixel(sw.region)
+	if r, _, _, _ := screenshot.Image.At(area.Min.X, area.Min.Y).RGBA(); r != 0 {
+		t.Fatalf("expected the black corner in the region %v", area)
+	}
+}
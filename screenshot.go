@@ -0,0 +1,193 @@
+package gwda
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+)
+
+// DecodedScreenshot A screenshot with the information needed to map its pixels to the points used by taps.
+//  Format: `png` or `jpeg`, depending on `AppiumSettings.WithScreenshotQuality`
+//  PixelSize: the size of the image
+//  PointSize: the size of the window in the current orientation
+//  Scale: the scale of the screen, the screenshot itself may be scaled differently
+//
+// The image is rotated upright if WDA returns it in portrait while the interface is in landscape.
+type DecodedScreenshot struct {
+	Image         image.Image
+	Format        string
+	PixelSize     Size
+	PointSize     Size
+	Scale         float64
+	StatusBarSize Size
+	Orientation   Orientation
+}
+
+// ratio Returns the pixels per point on both axes
+func (s DecodedScreenshot) ratio() (x, y float64) {
+	if s.PointSize.Width <= 0 || s.PointSize.Height <= 0 {
+		if s.Scale > 0 {
+			return s.Scale, s.Scale
+		}
+		return 1, 1
+	}
+	return float64(s.PixelSize.Width) / float64(s.PointSize.Width), float64(s.PixelSize.Height) / float64(s.PointSize.Height)
+}
+
+// PixelToPoint Converts a pixel of the image into a coordinate for `WebDriver.TapFloat` etc.
+func (s DecodedScreenshot) PixelToPoint(x, y int) (float64, float64) {
+	rx, ry := s.ratio()
+	bounds := s.Image.Bounds()
+	return float64(x-bounds.Min.X) / rx, float64(y-bounds.Min.Y) / ry
+}
+
+// PointToPixel Converts a coordinate in points into a pixel of the image
+func (s DecodedScreenshot) PointToPixel(x, y float64) (int, int) {
+	rx, ry := s.ratio()
+	bounds := s.Image.Bounds()
+	return bounds.Min.X + int(math.Round(x*rx)), bounds.Min.Y + int(math.Round(y*ry))
+}
+
+// PointRectToPixel Converts a rect in points, such as `WebElement.Rect`, into pixels of the image
+func (s DecodedScreenshot) PointRectToPixel(rect Rect) image.Rectangle {
+	minX, minY := s.PointToPixel(float64(rect.X), float64(rect.Y))
+	maxX, maxY := s.PointToPixel(float64(rect.X+rect.Width), float64(rect.Y+rect.Height))
+	return image.Rect(minX, minY, maxX, maxY).Intersect(s.Image.Bounds())
+}
+
+// Tap Taps at a pixel of the screenshot
+func (s DecodedScreenshot) Tap(driver WebDriver, x, y int) error {
+	px, py := s.PixelToPoint(x, y)
+	return driver.TapFloat(px, py)
+}
+
+// StatusBarRect Returns the pixels of the status bar, to be ignored when comparing screenshots
+func (s DecodedScreenshot) StatusBarRect() image.Rectangle {
+	return s.PointRectToPixel(Rect{Size: s.StatusBarSize})
+}
+
+// uprightScreenshot Rotates a portrait screenshot of a landscape interface,
+// so that the image matches the coordinates of the window.
+// An upside-down portrait cannot be told from its size and is left as is.
+func uprightScreenshot(img image.Image, orientation Orientation, windowSize Size) image.Image {
+	bounds := img.Bounds()
+	imagePortrait := bounds.Dy() > bounds.Dx()
+	windowPortrait := windowSize.Height > windowSize.Width
+	if windowSize.Width == windowSize.Height || imagePortrait == windowPortrait {
+		return img
+	}
+	switch orientation {
+	case OrientationLandscapeLeft:
+		return rotateImage(img, false)
+	case OrientationLandscapeRight:
+		return rotateImage(img, true)
+	}
+	return img
+}
+
+// rotateImage Rotates the image by 90 degrees
+func rotateImage(img image.Image, clockwise bool) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, h, w))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := img.At(bounds.Min.X+x, bounds.Min.Y+y)
+			if clockwise {
+				dst.Set(h-1-y, x, c)
+			} else {
+				dst.Set(y, w-1-x, c)
+			}
+		}
+	}
+	return dst
+}
+
+// ElementScreenshotOption Configure the behavior of `WebElement.ScreenshotImage`
+type ElementScreenshotOption func(es *elementScreenshot)
+
+// WithElementScreenshotPadding Extends the screenshot around the element, in points.
+// The screenshot is cropped from a screenshot of the screen.
+func WithElementScreenshotPadding(points int) ElementScreenshotOption {
+	return func(es *elementScreenshot) {
+		es.padding = points
+	}
+}
+
+// WithElementScreenshotMask Covers other elements, such as a clock or an avatar, with the mask color.
+// The screenshot is cropped from a screenshot of the screen.
+func WithElementScreenshotMask(elements ...WebElement) ElementScreenshotOption {
+	return func(es *elementScreenshot) {
+		es.masks = append(es.masks, elements...)
+	}
+}
+
+// WithElementScreenshotMaskColor Defaults to `color.Black`
+func WithElementScreenshotMaskColor(c color.Color) ElementScreenshotOption {
+	return func(es *elementScreenshot) {
+		es.maskColor = c
+	}
+}
+
+// WithElementScreenshotCrop Always crops the screenshot of the screen
+// instead of requesting the screenshot of the element
+func WithElementScreenshotCrop() ElementScreenshotOption {
+	return func(es *elementScreenshot) {
+		es.forceCrop = true
+	}
+}
+
+type elementScreenshot struct {
+	padding   int
+	masks     []WebElement
+	maskColor color.Color
+	forceCrop bool
+}
+
+func newElementScreenshot(opts ...ElementScreenshotOption) *elementScreenshot {
+	es := &elementScreenshot{maskColor: color.Black}
+	for _, opt := range opts {
+		opt(es)
+	}
+	return es
+}
+
+func (es *elementScreenshot) needsCrop() bool {
+	return es.forceCrop || es.padding != 0 || len(es.masks) != 0
+}
+
+// crop Crops the element out of a screenshot of the screen
+func (es *elementScreenshot) crop(driver WebDriver, elem WebElement) (img *image.RGBA, err error) {
+	var rect Rect
+	if rect, err = elem.Rect(); err != nil {
+		return nil, err
+	}
+	maskRects := make([]Rect, len(es.masks))
+	for i := range es.masks {
+		if maskRects[i], err = es.masks[i].Rect(); err != nil {
+			return nil, err
+		}
+	}
+	var screenshot DecodedScreenshot
+	if screenshot, err = driver.ScreenshotImage(); err != nil {
+		return nil, err
+	}
+	return es.cropScreenshot(screenshot, rect, maskRects), nil
+}
+
+func (es *elementScreenshot) cropScreenshot(screenshot DecodedScreenshot, rect Rect, maskRects []Rect) *image.RGBA {
+	rect.X, rect.Y = rect.X-es.padding, rect.Y-es.padding
+	rect.Width, rect.Height = rect.Width+2*es.padding, rect.Height+2*es.padding
+	area := screenshot.PointRectToPixel(rect)
+
+	img := image.NewRGBA(image.Rect(0, 0, area.Dx(), area.Dy()))
+	draw.Draw(img, img.Bounds(), screenshot.Image, area.Min, draw.Src)
+	for _, maskRect := range maskRects {
+		mask := screenshot.PointRectToPixel(maskRect).Intersect(area).Sub(area.Min)
+		draw.Draw(img, mask, image.NewUniform(es.maskColor), image.Point{}, draw.Src)
+	}
+	return img
+}
@@ -0,0 +1,75 @@
+package gwda
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func TestDecodedScreenshot_PixelToPoint(t *testing.T) {
+	screenshot := DecodedScreenshot{
+		Image:     image.NewRGBA(image.Rect(0, 0, 1170, 2532)),
+		PixelSize: Size{Width: 1170, Height: 2532},
+		PointSize: Size{Width: 390, Height: 844},
+		Scale:     3,
+	}
+	if x, y := screenshot.PixelToPoint(585, 1266); x != 195 || y != 422 {
+		t.Fatalf("unexpected point: (%v, %v)", x, y)
+	}
+	if x, y := screenshot.PointToPixel(195, 422); x != 585 || y != 1266 {
+		t.Fatalf("unexpected pixel: (%v, %v)", x, y)
+	}
+	rect := screenshot.PointRectToPixel(Rect{Point: Point{X: 380, Y: 10}, Size: Size{Width: 20, Height: 20}})
+	if rect != image.Rect(1140, 30, 1170, 90) {
+		t.Fatalf("unexpected rect: %v", rect)
+	}
+
+	screenshot.StatusBarSize = Size{Width: 390, Height: 47}
+	if rect := screenshot.StatusBarRect(); rect != image.Rect(0, 0, 1170, 141) {
+		t.Fatalf("unexpected status bar: %v", rect)
+	}
+
+	// a screenshot scaled down by the screenshot quality
+	screenshot.Image = image.NewRGBA(image.Rect(0, 0, 585, 1266))
+	screenshot.PixelSize = Size{Width: 585, Height: 1266}
+	if x, y := screenshot.PixelToPoint(585, 1266); x != 390 || y != 844 {
+		t.Fatalf("unexpected point: (%v, %v)", x, y)
+	}
+}
+
+func Test_uprightScreenshot(t *testing.T) {
+	// a portrait framebuffer with a marker at the top left of the device
+	img := image.NewRGBA(image.Rect(0, 0, 20, 40))
+	img.Set(0, 0, color.White)
+
+	// home button on the right: the top of the device is on the left
+	upright := uprightScreenshot(img, OrientationLandscapeLeft, Size{Width: 80, Height: 40})
+	if upright.Bounds() != image.Rect(0, 0, 40, 20) || upright.At(0, 19) != color.RGBAModel.Convert(color.White) {
+		t.Fatalf("unexpected rotation: %v", upright.Bounds())
+	}
+	upright = uprightScreenshot(img, OrientationLandscapeRight, Size{Width: 80, Height: 40})
+	if upright.Bounds() != image.Rect(0, 0, 40, 20) || upright.At(39, 0) != color.RGBAModel.Convert(color.White) {
+		t.Fatalf("unexpected rotation: %v", upright.Bounds())
+	}
+	if uprightScreenshot(img, OrientationPortrait, Size{Width: 10, Height: 20}) != image.Image(img) {
+		t.Fatal("expected a portrait screenshot to be left as is")
+	}
+}
+
+func Test_elementScreenshot_cropScreenshot(t *testing.T) {
+	screen := image.NewRGBA(image.Rect(0, 0, 200, 400))
+	draw.Draw(screen, screen.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	screenshot := DecodedScreenshot{Image: screen, PixelSize: Size{Width: 200, Height: 400}, PointSize: Size{Width: 100, Height: 200}, Scale: 2}
+
+	es := newElementScreenshot(WithElementScreenshotPadding(5), WithElementScreenshotMaskColor(color.RGBA{R: 255, A: 255}))
+	img := es.cropScreenshot(screenshot, Rect{Point: Point{X: 10, Y: 20}, Size: Size{Width: 30, Height: 10}},
+		[]Rect{{Point: Point{X: 0, Y: 0}, Size: Size{Width: 10, Height: 20}}})
+	if img.Bounds() != image.Rect(0, 0, 80, 40) {
+		t.Fatalf("unexpected size: %v", img.Bounds())
+	}
+	// the mask overlaps the padding at the top left
+	if img.RGBAAt(0, 0) != (color.RGBA{R: 255, A: 255}) || img.RGBAAt(10, 10) != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Fatalf("unexpected mask: %v %v", img.RGBAAt(0, 0), img.RGBAAt(10, 10))
+	}
+}
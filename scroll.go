@@ -0,0 +1,360 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CellIteratorOption Configure the behavior of CellIterator
+type CellIteratorOption func(it *CellIterator)
+
+// WithCellDistance The distance of each scroll, see `WebElement.ScrollDirection`.
+//  Defaults to `0.5`
+func WithCellDistance(distance float64) CellIteratorOption {
+	return func(it *CellIterator) {
+		it.distance = distance
+	}
+}
+
+// WithCellMaxScrolls The maximum number of scrolls before the iteration gives up.
+//  Defaults to `30`
+func WithCellMaxScrolls(n int) CellIteratorOption {
+	return func(it *CellIterator) {
+		it.maxScrolls = n
+	}
+}
+
+// WithCellKey Identifies a cell so that it is yielded exactly once.
+//  Defaults to the element UID combined with its text,
+//  since reused cells can keep the UID while displaying other content.
+func WithCellKey(fn func(cell WebElement) (string, error)) CellIteratorOption {
+	return func(it *CellIterator) {
+		it.key = fn
+	}
+}
+
+// WithCellStopWhen Stops the iteration after yielding the first cell that matches the predicate.
+func WithCellStopWhen(predicate func(cell WebElement) (bool, error)) CellIteratorOption {
+	return func(it *CellIterator) {
+		it.stopWhen = predicate
+	}
+}
+
+// CellIterator Scrolls a table or collection view and yields each newly visible cell exactly once.
+// The end of the content is reached when the visible cells no longer change after a scroll.
+//
+//  it := table.IterateCells(gwda.DirectionDown)
+//  for it.Next() {
+//  	cell := it.Cell()
+//  }
+//  if err := it.Err(); err != nil {
+//  }
+type CellIterator struct {
+	container  WebElement
+	direction  Direction
+	distance   float64
+	maxScrolls int
+	key        func(cell WebElement) (string, error)
+	stopWhen   func(cell WebElement) (bool, error)
+
+	seen      map[string]bool
+	pending   []WebElement
+	cell      WebElement
+	signature string
+	scrolls   int
+	started   bool
+	done      bool
+	matched   bool
+	err       error
+}
+
+func newCellIterator(container WebElement, direction Direction, opts ...CellIteratorOption) *CellIterator {
+	it := &CellIterator{
+		container:  container,
+		direction:  direction,
+		distance:   0.5,
+		maxScrolls: 30,
+		key:        defaultCellKey,
+		seen:       make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(it)
+	}
+	return it
+}
+
+func defaultCellKey(cell WebElement) (string, error) {
+	text, err := cell.Text()
+	if err != nil {
+		return "", err
+	}
+	return cell.UID() + "\x00" + text, nil
+}
+
+// Next Advances to the next unseen cell, scrolling the container when all visible cells were yielded.
+// It returns false when the end of the content is reached, the predicate matched or an error occurred.
+func (it *CellIterator) Next() bool {
+	it.cell = nil
+	for {
+		if it.err != nil || it.matched {
+			return false
+		}
+		if len(it.pending) != 0 {
+			it.cell, it.pending = it.pending[0], it.pending[1:]
+			if it.stopWhen != nil {
+				matched, err := it.stopWhen(it.cell)
+				if err != nil {
+					it.err, it.cell = err, nil
+					return false
+				}
+				it.matched = matched
+			}
+			return true
+		}
+		if it.done {
+			return false
+		}
+		if it.started {
+			if it.scrolls >= it.maxScrolls {
+				it.done = true
+				return false
+			}
+			if it.err = it.container.ScrollDirection(it.direction, it.distance); it.err != nil {
+				return false
+			}
+			it.scrolls++
+		}
+		it.started = true
+		it.err = it.collect()
+	}
+}
+
+func (it *CellIterator) collect() error {
+	cells, err := it.container.FindVisibleCells()
+	if err != nil && !errors.Is(err, errNoSuchElement) {
+		return err
+	}
+	keys := make([]string, len(cells))
+	for i := range cells {
+		if keys[i], err = it.key(cells[i]); err != nil {
+			return err
+		}
+	}
+
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+	signature := strings.Join(sorted, "\x01")
+	if it.scrolls != 0 && signature == it.signature {
+		it.done = true
+		return nil
+	}
+	it.signature = signature
+
+	for i := range cells {
+		if it.seen[keys[i]] {
+			continue
+		}
+		it.seen[keys[i]] = true
+		it.pending = append(it.pending, cells[i])
+	}
+	return nil
+}
+
+// Cell Returns the cell of the current iteration step
+func (it *CellIterator) Cell() WebElement {
+	return it.cell
+}
+
+// Err Returns the error that stopped the iteration, if any
+func (it *CellIterator) Err() error {
+	return it.err
+}
+
+// Matched Whether the iteration was stopped by the `WithCellStopWhen` predicate
+func (it *CellIterator) Matched() bool {
+	return it.matched
+}
+
+// Collect Iterates over the remaining cells and returns them
+func (it *CellIterator) Collect() (cells []WebElement, err error) {
+	for it.Next() {
+		cells = append(cells, it.Cell())
+	}
+	return cells, it.Err()
+}
+
+// ScrollUntilOption Configure the behavior of `WebDriver.ScrollUntil`
+type ScrollUntilOption func(su *scrollUntil)
+
+// WithScrollBidirectional Searches in the opposite direction as well
+// when the element was not found after `maxSwipes` swipes.
+func WithScrollBidirectional() ScrollUntilOption {
+	return func(su *scrollUntil) {
+		su.bidirectional = true
+	}
+}
+
+// WithScrollW3CSwipe Swipes with W3C actions across the container instead of `WebElement.SwipeDirection`.
+//  ratio: The fraction of the container size covered by each swipe. The default value is 0.5
+func WithScrollW3CSwipe(ratio ...float64) ScrollUntilOption {
+	return func(su *scrollUntil) {
+		su.w3c = true
+		if len(ratio) != 0 && ratio[0] > 0 && ratio[0] <= 1 {
+			su.ratio = ratio[0]
+		}
+	}
+}
+
+// WithScrollVelocity The velocity passed to `WebElement.SwipeDirection`
+func WithScrollVelocity(velocity float64) ScrollUntilOption {
+	return func(su *scrollUntil) {
+		su.velocity = velocity
+	}
+}
+
+type scrollUntil struct {
+	wd        WebDriver
+	container WebElement
+	by        BySelector
+	visible   Rect
+
+	bidirectional bool
+	w3c           bool
+	ratio         float64
+	velocity      float64
+}
+
+func newScrollUntil(wd WebDriver, container WebElement, by BySelector, opts ...ScrollUntilOption) (su *scrollUntil, err error) {
+	su = &scrollUntil{wd: wd, container: container, by: by, ratio: 0.5}
+	for _, opt := range opts {
+		opt(su)
+	}
+	if container == nil {
+		// without a container only W3C swipes across the whole window are possible
+		su.w3c = true
+		var size Size
+		if size, err = wd.WindowSize(); err != nil {
+			return nil, err
+		}
+		su.visible = Rect{Size: size}
+		return su, nil
+	}
+	if su.visible, err = container.Rect(); err != nil {
+		return nil, err
+	}
+	return su, nil
+}
+
+func (su *scrollUntil) run(direction Direction, maxSwipes int) (element WebElement, err error) {
+	if maxSwipes < 0 {
+		maxSwipes = 0
+	}
+	directions := []Direction{direction}
+	if su.bidirectional {
+		directions = append(directions, oppositeDirection(direction))
+	}
+	for i, dir := range directions {
+		swipes := maxSwipes
+		if i != 0 {
+			// go back past the starting position
+			swipes = maxSwipes * 2
+		}
+		for n := 0; ; n++ {
+			if element, err = su.lookup(); err != nil || element != nil {
+				return element, err
+			}
+			if n >= swipes {
+				break
+			}
+			if err = su.swipe(dir); err != nil {
+				return nil, err
+			}
+		}
+	}
+	using, value := su.by.getUsingAndValue()
+	return nil, fmt.Errorf("%w: unable to scroll to an element using '%s', value '%s'", errNoSuchElement, using, value)
+}
+
+// lookup returns the first displayed element whose center lies in the visible rect of the container
+func (su *scrollUntil) lookup() (element WebElement, err error) {
+	var elements []WebElement
+	if su.container != nil {
+		elements, err = su.container.FindElements(su.by)
+	} else {
+		elements, err = su.wd.FindElements(su.by)
+	}
+	if err != nil {
+		if errors.Is(err, errNoSuchElement) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for _, elem := range elements {
+		var displayed bool
+		if displayed, err = elem.IsDisplayed(); err != nil {
+			return nil, err
+		}
+		if !displayed {
+			continue
+		}
+		var rect Rect
+		if rect, err = elem.Rect(); err != nil {
+			return nil, err
+		}
+		if rect.Width == 0 || rect.Height == 0 {
+			continue
+		}
+		if su.visible.contains(rect.X+rect.Width/2, rect.Y+rect.Height/2) {
+			return elem, nil
+		}
+	}
+	return nil, nil
+}
+
+// swipe moves the content in the given direction, so the finger goes the opposite way
+func (su *scrollUntil) swipe(direction Direction) error {
+	if !su.w3c {
+		if su.velocity > 0 {
+			return su.container.SwipeDirection(oppositeDirection(direction), su.velocity)
+		}
+		return su.container.SwipeDirection(oppositeDirection(direction))
+	}
+	centerX := float64(su.visible.X) + float64(su.visible.Width)/2
+	centerY := float64(su.visible.Y) + float64(su.visible.Height)/2
+	offsetX := float64(su.visible.Width) * su.ratio / 2
+	offsetY := float64(su.visible.Height) * su.ratio / 2
+	var fromX, fromY, toX, toY = centerX, centerY, centerX, centerY
+	switch direction {
+	case DirectionDown:
+		fromY, toY = centerY+offsetY, centerY-offsetY
+	case DirectionUp:
+		fromY, toY = centerY-offsetY, centerY+offsetY
+	case DirectionRight:
+		fromX, toX = centerX+offsetX, centerX-offsetX
+	case DirectionLeft:
+		fromX, toX = centerX-offsetX, centerX+offsetX
+	default:
+		return fmt.Errorf("unsupported direction: %s", direction)
+	}
+	return su.wd.PerformW3CActions(NewW3CActions().SwipeFloat(fromX, fromY, toX, toY))
+}
+
+func oppositeDirection(direction Direction) Direction {
+	switch direction {
+	case DirectionUp:
+		return DirectionDown
+	case DirectionDown:
+		return DirectionUp
+	case DirectionLeft:
+		return DirectionRight
+	case DirectionRight:
+		return DirectionLeft
+	}
+	return direction
+}
+
+func (r Rect) contains(x, y int) bool {
+	return x >= r.X && x < r.X+r.Width && y >= r.Y && y < r.Y+r.Height
+}
@@ -0,0 +1,165 @@
+package gwda
+
+import (
+	"strconv"
+	"testing"
+)
+
+type fakeCell struct {
+	WebElement
+	id, text string
+}
+
+func (c fakeCell) UID() string { return c.id }
+
+func (c fakeCell) Text() (string, error) { return c.text, nil }
+
+// fakeList shows `window` rows of `total` at a time and moves by `step` rows per scroll.
+type fakeList struct {
+	WebElement
+	total, window, step, offset int
+	scrolls                     int
+}
+
+func (l *fakeList) FindVisibleCells() ([]WebElement, error) {
+	var cells []WebElement
+	for i := l.offset; i < l.offset+l.window && i < l.total; i++ {
+		cells = append(cells, fakeCell{id: "cell" + strconv.Itoa(i), text: "row " + strconv.Itoa(i)})
+	}
+	return cells, nil
+}
+
+func (l *fakeList) ScrollDirection(_ Direction, _ ...float64) error {
+	l.scrolls++
+	if l.offset+l.window < l.total {
+		l.offset += l.step
+	}
+	return nil
+}
+
+func TestCellIterator(t *testing.T) {
+	list := &fakeList{total: 10, window: 4, step: 3}
+	cells, err := newCellIterator(list, DirectionDown).Collect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cells) != list.total {
+		t.Fatalf("expected %d cells, got %d", list.total, len(cells))
+	}
+	for i := range cells {
+		if text, _ := cells[i].Text(); text != "row "+strconv.Itoa(i) {
+			t.Fatalf("cell %d: unexpected %q", i, text)
+		}
+	}
+	if list.scrolls > 4 {
+		t.Fatalf("expected the end to be detected, scrolled %d times", list.scrolls)
+	}
+}
+
+func TestCellIterator_StopWhen(t *testing.T) {
+	list := &fakeList{total: 50, window: 5, step: 5}
+	it := newCellIterator(list, DirectionDown, WithCellStopWhen(func(cell WebElement) (bool, error) {
+		text, err := cell.Text()
+		return text == "row 12", err
+	}))
+	var last WebElement
+	for it.Next() {
+		last = it.Cell()
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if !it.Matched() {
+		t.Fatal("expected the predicate to match")
+	}
+	if text, _ := last.Text(); text != "row 12" {
+		t.Fatalf("unexpected last cell %q", text)
+	}
+}
+
+func TestCellIterator_MaxScrolls(t *testing.T) {
+	list := &fakeList{total: 100, window: 5, step: 5}
+	cells, err := newCellIterator(list, DirectionDown, WithCellMaxScrolls(2)).Collect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cells) != 15 || list.scrolls != 2 {
+		t.Fatalf("unexpected %d cells after %d scrolls", len(cells), list.scrolls)
+	}
+}
+
+type fakeTarget struct {
+	WebElement
+	rect Rect
+}
+
+func (e fakeTarget) IsDisplayed() (bool, error) { return true, nil }
+
+func (e fakeTarget) Rect() (Rect, error) { return e.rect, nil }
+
+// fakeLazyList shows the target at position `at`, one swipe earlier it is loaded below the visible rect.
+type fakeLazyList struct {
+	WebElement
+	at          int
+	position    int
+	swipes      []Direction
+}
+
+func (l *fakeLazyList) Rect() (Rect, error) {
+	return Rect{Point{0, 100}, Size{300, 400}}, nil
+}
+
+func (l *fakeLazyList) SwipeDirection(direction Direction, _ ...float64) error {
+	l.swipes = append(l.swipes, direction)
+	if direction == DirectionUp {
+		l.position++
+	} else {
+		l.position--
+	}
+	return nil
+}
+
+func (l *fakeLazyList) FindElements(_ BySelector) ([]WebElement, error) {
+	switch l.position {
+	case l.at - 1:
+		return []WebElement{fakeTarget{rect: Rect{Point{0, 600}, Size{300, 44}}}}, nil
+	case l.at:
+		return []WebElement{fakeTarget{rect: Rect{Point{0, 300}, Size{300, 44}}}}, nil
+	default:
+		return nil, errNoSuchElement
+	}
+}
+
+func Test_scrollUntil(t *testing.T) {
+	list := &fakeLazyList{at: 4}
+	su, err := newScrollUntil(nil, list, BySelector{Name: "target"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	elem, err := su.run(DirectionDown, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rect, _ := elem.Rect(); rect.Y != 300 || len(list.swipes) != 4 {
+		t.Fatalf("unexpected element %+v after %d swipes", rect, len(list.swipes))
+	}
+
+	list = &fakeLazyList{at: -2}
+	if su, err = newScrollUntil(nil, list, BySelector{Name: "target"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = su.run(DirectionDown, 2); err == nil {
+		t.Fatal("expected the element not to be found")
+	}
+
+	list = &fakeLazyList{at: -2}
+	if su, err = newScrollUntil(nil, list, BySelector{Name: "target"}, WithScrollBidirectional()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = su.run(DirectionDown, 2); err != nil {
+		t.Fatal(err)
+	}
+	if list.position != -2 || len(list.swipes) != 6 {
+		t.Fatalf("unexpected position %d after %d swipes", list.position, len(list.swipes))
+	}
+}
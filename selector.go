@@ -0,0 +1,107 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SuggestSelectors Proposes selectors of the element, the most robust first, see `SourceNode.SuggestSelectors`.
+// The element is located in the current `WebDriver.Source` by its type, rect, name and label.
+func SuggestSelectors(driver WebDriver, elem WebElement) (selectors []BySelector, err error) {
+	var target SourceNode
+	if target.Type, err = elem.Type(); err != nil {
+		return nil, err
+	}
+	if target.Rect, err = elem.Rect(); err != nil {
+		return nil, err
+	}
+	if target.Name, err = elem.GetAttribute(NewElementAttribute().WithName("")); err != nil {
+		return nil, err
+	}
+	if target.Label, err = elem.GetAttribute(NewElementAttribute().WithLabel("")); err != nil {
+		return nil, err
+	}
+
+	root, err := SourceTree(driver)
+	if err != nil {
+		return nil, err
+	}
+	nodes := root.FindAll(func(n *SourceNode) bool {
+		return n.Type == target.Type && n.Rect == target.Rect
+	})
+	if len(nodes) > 1 {
+		// e.g. a cell and its content of the same type
+		var named []*SourceNode
+		for _, n := range nodes {
+			if n.Name == target.Name && n.Label == target.Label {
+				named = append(named, n)
+			}
+		}
+		if len(named) != 0 {
+			nodes = named
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, errors.New("suggest selectors: element not found in the source tree")
+	}
+	return nodes[0].SuggestSelectors(), nil
+}
+
+// SuggestSelectors Proposes selectors of the node which match it only, in the tree of the node, the most robust first:
+//  the accessibility id, if unique
+//  the predicates by type and name, or by type and label, if unique
+//  the class chain by type and name or label, indexed among the matches if needed
+//  the XPath by type and name or label, if unique
+//  the absolute XPath, which breaks as soon as the layout changes
+func (n *SourceNode) SuggestSelectors() (selectors []BySelector) {
+	root := n
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	unique := func(match func(node *SourceNode) bool) bool {
+		return len(root.FindAll(match)) == 1
+	}
+	sameType := func(node *SourceNode) bool { return node.Type == n.Type }
+
+	if n.Name != "" && unique(func(node *SourceNode) bool { return node.Name == n.Name }) {
+		selectors = append(selectors, BySelector{AccessibilityId: n.Name})
+	}
+	uniqueName := n.Name != "" && unique(func(node *SourceNode) bool { return sameType(node) && node.Name == n.Name })
+	uniqueLabel := n.Label != "" && unique(func(node *SourceNode) bool { return sameType(node) && node.Label == n.Label })
+	if uniqueName {
+		selectors = append(selectors, BySelector{Predicate: fmt.Sprintf("type == %s AND name == %s", predicateLiteral(n.Type), predicateLiteral(n.Name))})
+	}
+	if uniqueLabel && !(uniqueName && n.Label == n.Name) {
+		selectors = append(selectors, BySelector{Predicate: fmt.Sprintf("type == %s AND label == %s", predicateLiteral(n.Type), predicateLiteral(n.Label))})
+	}
+
+	// the class chain and the XPath prefer the name, as the label is usually localized
+	attr, text, match := "", "", sameType
+	switch {
+	case n.Name != "" && (uniqueName || !uniqueLabel):
+		attr, text = "name", n.Name
+		match = func(node *SourceNode) bool { return sameType(node) && node.Name == n.Name }
+	case n.Label != "":
+		attr, text = "label", n.Label
+		match = func(node *SourceNode) bool { return sameType(node) && node.Label == n.Label }
+	}
+	chain := "**/" + n.Type
+	if attr != "" {
+		chain += fmt.Sprintf("[`%s == %s`]", attr, predicateLiteral(text))
+	}
+	// the index of the class chain counts the matches in document order
+	if matches := root.FindAll(match); len(matches) > 1 {
+		for i, node := range matches {
+			if node == n {
+				chain += fmt.Sprintf("[%d]", i+1)
+				break
+			}
+		}
+	}
+	selectors = append(selectors, BySelector{ClassChain: chain})
+	if attr != "" && unique(match) {
+		selectors = append(selectors, BySelector{XPath: fmt.Sprintf("//%s[@%s=%s]", n.Type, attr, xpathLiteral(text))})
+	}
+	selectors = append(selectors, BySelector{XPath: absoluteXPath(n)})
+	return
+}
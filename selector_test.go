@@ -0,0 +1,104 @@
+package gwda
+
+import (
+	"reflect"
+	"testing"
+)
+
+const selectorSource = `{"type":"Application","name":"Demo","rect":{"x":0,"y":0,"width":320,"height":480},"children":[
+{"type":"Window","rect":{"x":0,"y":0,"width":320,"height":480},"children":[
+{"type":"Cell","name":"row","label":"Alice","rect":{"x":0,"y":0,"width":320,"height":40},"children":[
+{"type":"Button","name":"delete","label":"Delete","rect":{"x":260,"y":0,"width":60,"height":40}}]},
+{"type":"Cell","name":"row","label":"Bob","rect":{"x":0,"y":40,"width":320,"height":40},"children":[
+{"type":"Button","name":"delete","label":"Delete","rect":{"x":260,"y":40,"width":60,"height":40}}]},
+{"type":"Other","rect":{"x":0,"y":80,"width":320,"height":40}},
+{"type":"Other","rect":{"x":0,"y":120,"width":320,"height":40}},
+{"type":"Button","name":"add","label":"Add","rect":{"x":0,"y":440,"width":320,"height":40}}]}]}`
+
+func TestSourceNodeSuggestSelectors(t *testing.T) {
+	root, err := ParseSourceTree(selectorSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	window := root.Children[0]
+	for _, tt := range []struct {
+		node     *SourceNode
+		expected []BySelector
+	}{
+		{window.Children[4], []BySelector{
+			{AccessibilityId: "add"},
+			{Predicate: `type == "XCUIElementTypeButton" AND name == "add"`},
+			{Predicate: `type == "XCUIElementTypeButton" AND label == "Add"`},
+			{ClassChain: "**/XCUIElementTypeButton[`name == \"add\"`]"},
+			{XPath: "//XCUIElementTypeButton[@name='add']"},
+			{XPath: "/XCUIElementTypeApplication/XCUIElementTypeWindow[1]/XCUIElementTypeButton[1]"},
+		}},
+		// the name is shared, the label is not
+		{window.Children[1], []BySelector{
+			{Predicate: `type == "XCUIElementTypeCell" AND label == "Bob"`},
+			{ClassChain: "**/XCUIElementTypeCell[`label == \"Bob\"`]"},
+			{XPath: "//XCUIElementTypeCell[@label='Bob']"},
+			{XPath: "/XCUIElementTypeApplication/XCUIElementTypeWindow[1]/XCUIElementTypeCell[2]"},
+		}},
+		{window.Children[1].Children[0], []BySelector{
+			{ClassChain: "**/XCUIElementTypeButton[`name == \"delete\"`][2]"},
+			{XPath: "/XCUIElementTypeApplication/XCUIElementTypeWindow[1]/XCUIElementTypeCell[2]/XCUIElementTypeButton[1]"},
+		}},
+		{window.Children[3], []BySelector{
+			{ClassChain: "**/XCUIElementTypeOther[2]"},
+			{XPath: "/XCUIElementTypeApplication/XCUIElementTypeWindow[1]/XCUIElementTypeOther[2]"},
+		}},
+	} {
+		if got := tt.node.SuggestSelectors(); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s %q: expected %+v, got %+v", tt.node.Type, tt.node.Label, tt.expected, got)
+		}
+	}
+}
+
+type fakeSelectorElement struct {
+	WebElement
+	elemType string
+	rect     Rect
+	attrs    map[string]string
+}
+
+func (we fakeSelectorElement) Type() (string, error) {
+	return we.elemType, nil
+}
+
+func (we fakeSelectorElement) Rect() (Rect, error) {
+	return we.rect, nil
+}
+
+func (we fakeSelectorElement) GetAttribute(attr ElementAttribute) (string, error) {
+	return we.attrs[attr.getAttributeName()], nil
+}
+
+type fakeSelectorDriver struct {
+	WebDriver
+}
+
+func (wd fakeSelectorDriver) Source(srcOpt ...SourceOption) (string, error) {
+	return selectorSource, nil
+}
+
+func TestSuggestSelectors(t *testing.T) {
+	// the second of the delete buttons
+	elem := fakeSelectorElement{
+		elemType: "XCUIElementTypeButton",
+		rect:     Rect{Point{260, 40}, Size{60, 40}},
+		attrs:    map[string]string{"name": "delete", "label": "Delete"},
+	}
+	selectors, err := SuggestSelectors(fakeSelectorDriver{}, elem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selectors) == 0 || selectors[0].ClassChain != "**/XCUIElementTypeButton[`name == \"delete\"`][2]" {
+		t.Fatalf("unexpected selectors: %+v", selectors)
+	}
+
+	elem.rect.Y = 400
+	if _, err = SuggestSelectors(fakeSelectorDriver{}, elem); err == nil {
+		t.Fatal("expected an error for an element missing from the tree")
+	}
+}
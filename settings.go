@@ -0,0 +1,395 @@
+package gwda
+
+import "encoding/json"
+
+// AppiumSettings The settings of WDA, read with `GetAppiumSettings` and changed with `SetAppiumSettings`.
+// Only the settings given to a `With...` method are sent, the others keep their current value.
+//
+// Each getter reports false as second value if the setting is missing or of another type.
+type AppiumSettings struct {
+	values map[string]interface{}
+}
+
+func NewAppiumSettings() *AppiumSettings {
+	return &AppiumSettings{values: make(map[string]interface{})}
+}
+
+// GetAppiumSettings Returns the current settings of WDA
+func GetAppiumSettings(driver WebDriver) (*AppiumSettings, error) {
+	values, err := driver.GetAppiumSettings()
+	if err != nil {
+		return nil, err
+	}
+	return &AppiumSettings{values: values}, nil
+}
+
+// SetAppiumSettings Changes the settings of WDA and returns all of them
+func SetAppiumSettings(driver WebDriver, settings *AppiumSettings) (*AppiumSettings, error) {
+	values, err := driver.SetAppiumSettings(settings.values)
+	if err != nil {
+		return nil, err
+	}
+	return &AppiumSettings{values: values}, nil
+}
+
+func (settings AppiumSettings) MarshalJSON() ([]byte, error) {
+	return json.Marshal(settings.values)
+}
+
+func (settings *AppiumSettings) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &settings.values)
+}
+
+// WithMjpegServerScreenshotQuality The JPEG quality of the MJPEG frames, from 1 to 100.
+//  Defaults to `25`
+func (settings *AppiumSettings) WithMjpegServerScreenshotQuality(n int) *AppiumSettings {
+	settings.set("mjpegServerScreenshotQuality", n)
+	return settings
+}
+
+// MjpegServerScreenshotQuality See `WithMjpegServerScreenshotQuality`
+func (settings *AppiumSettings) MjpegServerScreenshotQuality() (int, bool) {
+	return settings.getInt("mjpegServerScreenshotQuality")
+}
+
+// WithMjpegServerFramerate The maximum frame rate of the MJPEG stream, from 1 to 60.
+//  Defaults to `10`
+func (settings *AppiumSettings) WithMjpegServerFramerate(n int) *AppiumSettings {
+	settings.set("mjpegServerFramerate", n)
+	return settings
+}
+
+// MjpegServerFramerate See `WithMjpegServerFramerate`
+func (settings *AppiumSettings) MjpegServerFramerate() (int, bool) {
+	return settings.getInt("mjpegServerFramerate")
+}
+
+// WithMjpegScalingFactor The percentage the MJPEG frames are scaled by, from 1 to 100.
+//  Defaults to `100`
+func (settings *AppiumSettings) WithMjpegScalingFactor(f float64) *AppiumSettings {
+	settings.set("mjpegScalingFactor", f)
+	return settings
+}
+
+// MjpegScalingFactor See `WithMjpegScalingFactor`
+func (settings *AppiumSettings) MjpegScalingFactor() (float64, bool) {
+	return settings.getFloat("mjpegScalingFactor")
+}
+
+// WithMjpegFixOrientation Whether to rotate the MJPEG frames to the orientation of the interface.
+//  Defaults to `false`
+func (settings *AppiumSettings) WithMjpegFixOrientation(b bool) *AppiumSettings {
+	settings.set("mjpegFixOrientation", b)
+	return settings
+}
+
+// MjpegFixOrientation See `WithMjpegFixOrientation`
+func (settings *AppiumSettings) MjpegFixOrientation() (bool, bool) {
+	return settings.getBool("mjpegFixOrientation")
+}
+
+// WithScreenshotQuality The quality of `WebDriver.Screenshot`: 0 is the highest (PNG), 1 high (JPEG), 2 low (JPEG).
+//  Defaults to `1`
+func (settings *AppiumSettings) WithScreenshotQuality(n int) *AppiumSettings {
+	settings.set("screenshotQuality", n)
+	return settings
+}
+
+// ScreenshotQuality See `WithScreenshotQuality`
+func (settings *AppiumSettings) ScreenshotQuality() (int, bool) {
+	return settings.getInt("screenshotQuality")
+}
+
+// WithScreenshotOrientation One of `auto`, `portrait`, `portraitUpsideDown`, `landscapeRight` and `landscapeLeft`.
+//  Defaults to `auto`
+func (settings *AppiumSettings) WithScreenshotOrientation(s string) *AppiumSettings {
+	settings.set("screenshotOrientation", s)
+	return settings
+}
+
+// ScreenshotOrientation See `WithScreenshotOrientation`
+func (settings *AppiumSettings) ScreenshotOrientation() (string, bool) {
+	return settings.getString("screenshotOrientation")
+}
+
+// WithElementResponseAttributes The comma-separated attributes of the found elements,
+// only returned if `WithShouldUseCompactResponses` is false.
+//  Defaults to `type,label`
+func (settings *AppiumSettings) WithElementResponseAttributes(s string) *AppiumSettings {
+	settings.set("elementResponseAttributes", s)
+	return settings
+}
+
+// ElementResponseAttributes See `WithElementResponseAttributes`
+func (settings *AppiumSettings) ElementResponseAttributes() (string, bool) {
+	return settings.getString("elementResponseAttributes")
+}
+
+// WithShouldUseCompactResponses Whether the found elements are returned with their identifier only,
+// as the W3C standard does, which is faster.
+//  Defaults to `true`
+func (settings *AppiumSettings) WithShouldUseCompactResponses(b bool) *AppiumSettings {
+	settings.set("shouldUseCompactResponses", b)
+	return settings
+}
+
+// ShouldUseCompactResponses See `WithShouldUseCompactResponses`
+func (settings *AppiumSettings) ShouldUseCompactResponses() (bool, bool) {
+	return settings.getBool("shouldUseCompactResponses")
+}
+
+// WithKeyboardAutocorrection Whether the on-screen keyboard corrects the typed words.
+//  Defaults to `false`
+func (settings *AppiumSettings) WithKeyboardAutocorrection(b bool) *AppiumSettings {
+	settings.set("keyboardAutocorrection", b)
+	return settings
+}
+
+// KeyboardAutocorrection See `WithKeyboardAutocorrection`
+func (settings *AppiumSettings) KeyboardAutocorrection() (bool, bool) {
+	return settings.getBool("keyboardAutocorrection")
+}
+
+// WithKeyboardPrediction Whether the on-screen keyboard suggests words.
+//  Defaults to `false`
+func (settings *AppiumSettings) WithKeyboardPrediction(b bool) *AppiumSettings {
+	settings.set("keyboardPrediction", b)
+	return settings
+}
+
+// KeyboardPrediction See `WithKeyboardPrediction`
+func (settings *AppiumSettings) KeyboardPrediction() (bool, bool) {
+	return settings.getBool("keyboardPrediction")
+}
+
+// WithSnapshotMaxDepth The maximum depth of the elements tree, deeper elements are neither found nor listed by `WebDriver.Source`.
+//  Defaults to `50`
+func (settings *AppiumSettings) WithSnapshotMaxDepth(n int) *AppiumSettings {
+	settings.set("snapshotMaxDepth", n)
+	return settings
+}
+
+// SnapshotMaxDepth See `WithSnapshotMaxDepth`
+func (settings *AppiumSettings) SnapshotMaxDepth() (int, bool) {
+	return settings.getInt("snapshotMaxDepth")
+}
+
+// WithCustomSnapshotTimeout The number of seconds to wait for a snapshot of the elements tree.
+//  Defaults to `15`
+func (settings *AppiumSettings) WithCustomSnapshotTimeout(f float64) *AppiumSettings {
+	settings.set("customSnapshotTimeout", f)
+	return settings
+}
+
+// CustomSnapshotTimeout See `WithCustomSnapshotTimeout`
+func (settings *AppiumSettings) CustomSnapshotTimeout() (float64, bool) {
+	return settings.getFloat("customSnapshotTimeout")
+}
+
+// WithUseFirstMatch Whether `WebDriver.FindElement` returns the first match without resolving all matches,
+// which is faster but ignores elements that are not hittable.
+//  Defaults to `false`
+func (settings *AppiumSettings) WithUseFirstMatch(b bool) *AppiumSettings {
+	settings.set("useFirstMatch", b)
+	return settings
+}
+
+// UseFirstMatch See `WithUseFirstMatch`
+func (settings *AppiumSettings) UseFirstMatch() (bool, bool) {
+	return settings.getBool("useFirstMatch")
+}
+
+// WithBoundElementsByIndex Whether found elements are bound by their index instead of their identity,
+// which is faster for large trees but breaks once the tree changes.
+//  Defaults to `false`
+func (settings *AppiumSettings) WithBoundElementsByIndex(b bool) *AppiumSettings {
+	settings.set("boundElementsByIndex", b)
+	return settings
+}
+
+// BoundElementsByIndex See `WithBoundElementsByIndex`
+func (settings *AppiumSettings) BoundElementsByIndex() (bool, bool) {
+	return settings.getBool("boundElementsByIndex")
+}
+
+// WithReduceMotion Turns the `Reduce Motion` accessibility setting on or off.
+//  Defaults to `false`
+func (settings *AppiumSettings) WithReduceMotion(b bool) *AppiumSettings {
+	settings.set("reduceMotion", b)
+	return settings
+}
+
+// ReduceMotion See `WithReduceMotion`
+func (settings *AppiumSettings) ReduceMotion() (bool, bool) {
+	return settings.getBool("reduceMotion")
+}
+
+// WithDefaultActiveApplication The bundle identifier of the application to use if the active application cannot be detected.
+//  Defaults to `auto`
+func (settings *AppiumSettings) WithDefaultActiveApplication(s string) *AppiumSettings {
+	settings.set("defaultActiveApplication", s)
+	return settings
+}
+
+// DefaultActiveApplication See `WithDefaultActiveApplication`
+func (settings *AppiumSettings) DefaultActiveApplication() (string, bool) {
+	return settings.getString("defaultActiveApplication")
+}
+
+// WithActiveAppDetectionPoint The coordinate `x,y` of the element used to detect the active application.
+//  Defaults to `64.00,64.00`
+func (settings *AppiumSettings) WithActiveAppDetectionPoint(s string) *AppiumSettings {
+	settings.set("activeAppDetectionPoint", s)
+	return settings
+}
+
+// ActiveAppDetectionPoint See `WithActiveAppDetectionPoint`
+func (settings *AppiumSettings) ActiveAppDetectionPoint() (string, bool) {
+	return settings.getString("activeAppDetectionPoint")
+}
+
+// WithIncludeNonModalElements Whether to include the elements behind a modal view.
+//  Defaults to `false`
+func (settings *AppiumSettings) WithIncludeNonModalElements(b bool) *AppiumSettings {
+	settings.set("includeNonModalElements", b)
+	return settings
+}
+
+// IncludeNonModalElements See `WithIncludeNonModalElements`
+func (settings *AppiumSettings) IncludeNonModalElements() (bool, bool) {
+	return settings.getBool("includeNonModalElements")
+}
+
+// WithDefaultAlertAction Accepts or dismisses alerts automatically.
+func (settings *AppiumSettings) WithDefaultAlertAction(alertAction AlertAction) *AppiumSettings {
+	settings.set("defaultAlertAction", alertAction)
+	return settings
+}
+
+// DefaultAlertAction See `WithDefaultAlertAction`
+func (settings *AppiumSettings) DefaultAlertAction() (AlertAction, bool) {
+	v, ok := settings.getString("defaultAlertAction")
+	return AlertAction(v), ok
+}
+
+// WithAcceptAlertButtonSelector The class chain of the button tapped by `WebDriver.AlertAccept`.
+func (settings *AppiumSettings) WithAcceptAlertButtonSelector(s string) *AppiumSettings {
+	settings.set("acceptAlertButtonSelector", s)
+	return settings
+}
+
+// AcceptAlertButtonSelector See `WithAcceptAlertButtonSelector`
+func (settings *AppiumSettings) AcceptAlertButtonSelector() (string, bool) {
+	return settings.getString("acceptAlertButtonSelector")
+}
+
+// WithDismissAlertButtonSelector The class chain of the button tapped by `WebDriver.AlertDismiss`.
+func (settings *AppiumSettings) WithDismissAlertButtonSelector(s string) *AppiumSettings {
+	settings.set("dismissAlertButtonSelector", s)
+	return settings
+}
+
+// DismissAlertButtonSelector See `WithDismissAlertButtonSelector`
+func (settings *AppiumSettings) DismissAlertButtonSelector() (string, bool) {
+	return settings.getString("dismissAlertButtonSelector")
+}
+
+// WithWaitForIdleTimeout The number of seconds to wait for the application to become idle.
+//  Defaults to `10`
+func (settings *AppiumSettings) WithWaitForIdleTimeout(f float64) *AppiumSettings {
+	settings.set("waitForIdleTimeout", f)
+	return settings
+}
+
+// WaitForIdleTimeout See `WithWaitForIdleTimeout`
+func (settings *AppiumSettings) WaitForIdleTimeout() (float64, bool) {
+	return settings.getFloat("waitForIdleTimeout")
+}
+
+// WithAnimationCoolOffTimeout The number of seconds to wait for animations to finish.
+//  Defaults to `2`
+func (settings *AppiumSettings) WithAnimationCoolOffTimeout(f float64) *AppiumSettings {
+	settings.set("animationCoolOffTimeout", f)
+	return settings
+}
+
+// AnimationCoolOffTimeout See `WithAnimationCoolOffTimeout`
+func (settings *AppiumSettings) AnimationCoolOffTimeout() (float64, bool) {
+	return settings.getFloat("animationCoolOffTimeout")
+}
+
+// WithUseClearTextShortcut Whether `WebElement.Clear` selects all and deletes instead of pressing backspace repeatedly.
+//  Defaults to `true`
+func (settings *AppiumSettings) WithUseClearTextShortcut(b bool) *AppiumSettings {
+	settings.set("useClearTextShortcut", b)
+	return settings
+}
+
+// UseClearTextShortcut See `WithUseClearTextShortcut`
+func (settings *AppiumSettings) UseClearTextShortcut() (bool, bool) {
+	return settings.getBool("useClearTextShortcut")
+}
+
+// WithMaxTypingFrequency The maximum number of letters typed per second.
+//  Defaults to `60`
+func (settings *AppiumSettings) WithMaxTypingFrequency(n int) *AppiumSettings {
+	settings.set("maxTypingFrequency", n)
+	return settings
+}
+
+// MaxTypingFrequency See `WithMaxTypingFrequency`
+func (settings *AppiumSettings) MaxTypingFrequency() (int, bool) {
+	return settings.getInt("maxTypingFrequency")
+}
+
+// WithLimitXpathContextScope Whether XPath lookups from an element are limited to its descendants.
+//  Defaults to `true`
+func (settings *AppiumSettings) WithLimitXpathContextScope(b bool) *AppiumSettings {
+	settings.set("limitXpathContextScope", b)
+	return settings
+}
+
+// LimitXpathContextScope See `WithLimitXpathContextScope`
+func (settings *AppiumSettings) LimitXpathContextScope() (bool, bool) {
+	return settings.getBool("limitXpathContextScope")
+}
+
+func (settings *AppiumSettings) set(key string, value interface{}) {
+	if settings.values == nil {
+		settings.values = make(map[string]interface{})
+	}
+	settings.values[key] = value
+}
+
+func (settings *AppiumSettings) getFloat(key string) (float64, bool) {
+	switch v := settings.values[key].(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func (settings *AppiumSettings) getInt(key string) (int, bool) {
+	v, ok := settings.getFloat(key)
+	return int(v), ok
+}
+
+// getBool WDA may report booleans as numbers
+func (settings *AppiumSettings) getBool(key string) (bool, bool) {
+	if v, ok := settings.values[key].(bool); ok {
+		return v, true
+	}
+	v, ok := settings.getFloat(key)
+	return v != 0, ok
+}
+
+func (settings *AppiumSettings) getString(key string) (string, bool) {
+	v, ok := settings.values[key].(string)
+	return v, ok
+}
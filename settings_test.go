@@ -0,0 +1,68 @@
+package gwda
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAppiumSettings(t *testing.T) {
+	settings := NewAppiumSettings().
+		WithMjpegServerFramerate(30).
+		WithMjpegScalingFactor(50).
+		WithUseFirstMatch(true).
+		WithDefaultAlertAction(AlertActionAccept)
+	bsJSON, err := json.Marshal(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bsJSON) != `{"defaultAlertAction":"accept","mjpegScalingFactor":50,"mjpegServerFramerate":30,"useFirstMatch":true}` {
+		t.Fatalf("unexpected settings: %s", bsJSON)
+	}
+
+	// as returned by WebDriver.GetAppiumSettings
+	settings = new(AppiumSettings)
+	if err = json.Unmarshal([]byte(`{"mjpegServerFramerate":30,"reduceMotion":1,"defaultActiveApplication":"auto","snapshotMaxDepth":"50"}`), settings); err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := settings.MjpegServerFramerate(); !ok || n != 30 {
+		t.Fatalf("unexpected mjpegServerFramerate: %v %v", n, ok)
+	}
+	if b, ok := settings.ReduceMotion(); !ok || !b {
+		t.Fatalf("unexpected reduceMotion: %v %v", b, ok)
+	}
+	if s, ok := settings.DefaultActiveApplication(); !ok || s != "auto" {
+		t.Fatalf("unexpected defaultActiveApplication: %v %v", s, ok)
+	}
+	if _, ok := settings.SnapshotMaxDepth(); ok {
+		t.Fatal("expected a setting of another type to be reported as missing")
+	}
+	if _, ok := settings.UseFirstMatch(); ok {
+		t.Fatal("expected a missing setting to be reported as missing")
+	}
+}
+
+type fakeSettingsDriver struct {
+	WebDriver
+	values map[string]interface{}
+}
+
+func (wd *fakeSettingsDriver) SetAppiumSettings(settings map[string]interface{}) (map[string]interface{}, error) {
+	for k, v := range settings {
+		wd.values[k] = v
+	}
+	return wd.values, nil
+}
+
+func TestSetAppiumSettings(t *testing.T) {
+	wd := &fakeSettingsDriver{values: map[string]interface{}{"mjpegServerFramerate": 10.0, "reduceMotion": false}}
+	settings, err := SetAppiumSettings(wd, NewAppiumSettings().WithReduceMotion(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := settings.ReduceMotion(); !ok || !b {
+		t.Fatalf("unexpected reduceMotion: %v %v", b, ok)
+	}
+	if n, ok := settings.MjpegServerFramerate(); !ok || n != 10 {
+		t.Fatalf("unexpected mjpegServerFramerate: %v %v", n, ok)
+	}
+}
@@ -0,0 +1,188 @@
+package gwda
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// SourceNode An element of the application elements tree, see `WebDriver.Source` in json format
+type SourceNode struct {
+	// Type The element type, e.g. `XCUIElementTypeButton`
+	Type          string
+	Name          string
+	Label         string
+	Value         string
+	RawIdentifier string
+	Rect          Rect
+	IsEnabled     bool
+	IsVisible     bool
+	IsAccessible  bool
+
+	Parent   *SourceNode
+	Children []*SourceNode
+}
+
+// SourceTree Returns the application elements tree
+func SourceTree(driver WebDriver) (root *SourceNode, err error) {
+	var source string
+	if source, err = driver.Source(NewSourceOption().WithFormatAsJson()); err != nil {
+		return nil, err
+	}
+	return ParseSourceTree(source)
+}
+
+// ParseSourceTree Parses the elements tree returned by `WebDriver.Source` in json format
+func ParseSourceTree(source string) (root *SourceNode, err error) {
+	root = new(SourceNode)
+	if err = json.Unmarshal([]byte(source), root); err != nil {
+		return nil, fmt.Errorf("parse source: %w", err)
+	}
+	return root, nil
+}
+
+func (n *SourceNode) UnmarshalJSON(data []byte) (err error) {
+	var raw struct {
+		Type          string          `json:"type"`
+		Name          interface{}     `json:"name"`
+		Label         interface{}     `json:"label"`
+		Value         interface{}     `json:"value"`
+		RawIdentifier interface{}     `json:"rawIdentifier"`
+		Rect          json.RawMessage `json:"rect"`
+		IsEnabled     interface{}     `json:"isEnabled"`
+		IsVisible     interface{}     `json:"isVisible"`
+		IsAccessible  interface{}     `json:"isAccessible"`
+		Children      []*SourceNode   `json:"children"`
+	}
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	n.Type = raw.Type
+	if n.Type != "" && !strings.HasPrefix(n.Type, "XCUIElementType") {
+		n.Type = "XCUIElementType" + n.Type
+	}
+	n.Name = sourceString(raw.Name)
+	n.Label = sourceString(raw.Label)
+	n.Value = sourceString(raw.Value)
+	n.RawIdentifier = sourceString(raw.RawIdentifier)
+	n.IsEnabled = sourceBool(raw.IsEnabled)
+	n.IsVisible = sourceBool(raw.IsVisible)
+	n.IsAccessible = sourceBool(raw.IsAccessible)
+	if len(raw.Rect) != 0 {
+		var rect struct{ X, Y, Width, Height float64 }
+		if err = json.Unmarshal(raw.Rect, &rect); err != nil {
+			return err
+		}
+		n.Rect = Rect{
+			Point: Point{X: int(math.Round(rect.X)), Y: int(math.Round(rect.Y))},
+			Size:  Size{Width: int(math.Round(rect.Width)), Height: int(math.Round(rect.Height))},
+		}
+	}
+	n.Children = raw.Children
+	for _, child := range n.Children {
+		child.Parent = n
+	}
+	return nil
+}
+
+func sourceString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// sourceBool WDA reports booleans as "1" and "0"
+func sourceBool(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v == "1" || v == "true"
+	case float64:
+		return v != 0
+	}
+	return false
+}
+
+// Walk Visits the node and its descendants depth-first, in document order.
+// Returning false from fn skips the children of that node.
+func (n *SourceNode) Walk(fn func(node *SourceNode) bool) {
+	if !fn(n) {
+		return
+	}
+	for _, child := range n.Children {
+		child.Walk(fn)
+	}
+}
+
+// FindAll Returns the node and the descendants that match, in document order
+func (n *SourceNode) FindAll(match func(node *SourceNode) bool) (nodes []*SourceNode) {
+	n.Walk(func(node *SourceNode) bool {
+		if match(node) {
+			nodes = append(nodes, node)
+		}
+		return true
+	})
+	return
+}
+
+// Find Returns the first node that matches, or nil
+func (n *SourceNode) Find(match func(node *SourceNode) bool) (found *SourceNode) {
+	n.Walk(func(node *SourceNode) bool {
+		if found == nil && match(node) {
+			found = node
+		}
+		return found == nil
+	})
+	return
+}
+
+// FindByType Returns the node and the descendants of the element type
+func (n *SourceNode) FindByType(elemType ElementType) []*SourceNode {
+	typeName := elemType.String()
+	return n.FindAll(func(node *SourceNode) bool {
+		return node.Type == typeName
+	})
+}
+
+// Index The 1-based position among the siblings of the same type
+func (n *SourceNode) Index() int {
+	if n.Parent == nil {
+		return 1
+	}
+	index := 0
+	for _, sibling := range n.Parent.Children {
+		if sibling.Type == n.Type {
+			index++
+		}
+		if sibling == n {
+			break
+		}
+	}
+	return index
+}
+
+// Center Returns the center of the node's rect
+func (n *SourceNode) Center() (x, y float64) {
+	return float64(n.Rect.X) + float64(n.Rect.Width)/2, float64(n.Rect.Y) + float64(n.Rect.Height)/2
+}
+
+func (n *SourceNode) String() string {
+	ss := []string{n.Type}
+	for _, attr := range [][2]string{{"name", n.Name}, {"label", n.Label}, {"value", n.Value}} {
+		if attr[1] != "" {
+			ss = append(ss, attr[0]+"="+strconv.Quote(attr[1]))
+		}
+	}
+	ss = append(ss, fmt.Sprintf("{%d, %d, %d, %d}", n.Rect.X, n.Rect.Y, n.Rect.Width, n.Rect.Height))
+	return strings.Join(ss, " ")
+}
@@ -0,0 +1,410 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"time"
+)
+
+// ImageMatch A location of the template in the screenshot.
+//  Rect: in pixels of the screenshot
+//  PointRect: in points, only set by FindImage
+//  Confidence: the normalized cross-correlation, from -1 to 1
+//  Scale: the scale of the template that matched
+type ImageMatch struct {
+	Rect       image.Rectangle
+	PointRect  Rect
+	Confidence float64
+	Scale      float64
+}
+
+// TemplateOption Configure the behavior of MatchTemplate
+type TemplateOption func(tm *templateMatcher)
+
+// WithTemplateThreshold The lowest confidence of a match.
+//  Defaults to `0.9`
+func WithTemplateThreshold(threshold float64) TemplateOption {
+	return func(tm *templateMatcher) {
+		tm.threshold = threshold
+	}
+}
+
+// WithTemplateScales The scales the template is resized by before matching,
+// for templates captured on a device with another screen size.
+//  Defaults to `1`
+func WithTemplateScales(scales ...float64) TemplateOption {
+	return func(tm *templateMatcher) {
+		if len(scales) != 0 {
+			tm.scales = scales
+		}
+	}
+}
+
+// WithTemplateMaxResults Defaults to `10`
+func WithTemplateMaxResults(n int) TemplateOption {
+	return func(tm *templateMatcher) {
+		if n > 0 {
+			tm.maxResults = n
+		}
+	}
+}
+
+// WithTemplateRegion Limits the search to a region of the image, in pixels
+func WithTemplateRegion(region image.Rectangle) TemplateOption {
+	return func(tm *templateMatcher) {
+		tm.region = region
+	}
+}
+
+// WithTemplateTimeout The timeout of WaitForImage.
+//  Defaults to `DefaultWaitTimeout`
+func WithTemplateTimeout(timeout time.Duration) TemplateOption {
+	return func(tm *templateMatcher) {
+		tm.timeout = timeout
+	}
+}
+
+type templateMatcher struct {
+	threshold  float64
+	scales     []float64
+	maxResults int
+	region     image.Rectangle
+	timeout    time.Duration
+}
+
+func newTemplateMatcher(opts ...TemplateOption) *templateMatcher {
+	tm := &templateMatcher{
+		threshold:  0.9,
+		scales:     []float64{1},
+		maxResults: 10,
+		timeout:    DefaultWaitTimeout,
+	}
+	for _, opt := range opts {
+		opt(tm)
+	}
+	return tm
+}
+
+// templateMinSize The size of the template at the coarsest level of the search
+const templateMinSize = 12
+
+var errFlatTemplate = errors.New("template has no contrast")
+
+// MatchTemplate Searches the image for the template with normalized cross-correlation
+// and returns the matches, best first.
+// The image is searched on a downscaled copy first and the candidates are refined at full resolution.
+func MatchTemplate(img, template image.Image, opts ...TemplateOption) (matches []ImageMatch, err error) {
+	tm := newTemplateMatcher(opts...)
+
+	bounds := img.Bounds()
+	if !tm.region.Empty() {
+		bounds = bounds.Intersect(tm.region)
+	}
+	haystack := newGrayImage(img, bounds)
+	needle := newGrayImage(template, template.Bounds())
+
+	for _, scale := range tm.scales {
+		tpl := needle
+		if scale != 1 {
+			tpl = needle.resize(int(math.Round(float64(needle.w)*scale)), int(math.Round(float64(needle.h)*scale)))
+		}
+		if tpl.w < 1 || tpl.h < 1 || tpl.w > haystack.w || tpl.h > haystack.h {
+			continue
+		}
+		var found []ImageMatch
+		if found, err = tm.match(haystack, tpl); err != nil {
+			return nil, err
+		}
+		for i := range found {
+			found[i].Scale = scale
+			found[i].Rect = found[i].Rect.Add(bounds.Min)
+		}
+		matches = append(matches, found...)
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Confidence > matches[j].Confidence })
+	matches = suppressOverlaps(matches)
+	if len(matches) > tm.maxResults {
+		matches = matches[:tm.maxResults]
+	}
+	return matches, nil
+}
+
+func (tm *templateMatcher) match(haystack, tpl *grayImage) (matches []ImageMatch, err error) {
+	factor := 1
+	for factor < 8 && tpl.w/(factor*2) >= templateMinSize && tpl.h/(factor*2) >= templateMinSize {
+		factor *= 2
+	}
+
+	fine, err := newNCC(haystack, tpl)
+	if err != nil {
+		return nil, err
+	}
+	if factor == 1 {
+		return fine.peaks(0, 0, haystack.w-tpl.w, haystack.h-tpl.h, tm.threshold, 1), nil
+	}
+
+	coarseHaystack := haystack.resize(haystack.w/factor, haystack.h/factor)
+	coarseTpl := tpl.resize(tpl.w/factor, tpl.h/factor)
+	coarse, err := newNCC(coarseHaystack, coarseTpl)
+	if err == errFlatTemplate {
+		// the details of the template, e.g. thin text, are averaged out by the downscaling
+		return fine.peaks(0, 0, haystack.w-tpl.w, haystack.h-tpl.h, tm.threshold, 1), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// downscaling blurs the details, so the coarse level is more tolerant
+	candidates := coarse.peaks(0, 0, coarseHaystack.w-coarseTpl.w, coarseHaystack.h-coarseTpl.h, tm.threshold-0.25, 2)
+	if len(candidates) > 4*tm.maxResults {
+		candidates = candidates[:4*tm.maxResults]
+	}
+	for _, candidate := range candidates {
+		x, y := candidate.Rect.Min.X*factor, candidate.Rect.Min.Y*factor
+		refined := fine.peaks(x-2*factor, y-2*factor, x+2*factor, y+2*factor, tm.threshold, 1)
+		if len(refined) != 0 {
+			matches = append(matches, refined[0])
+		}
+	}
+	return matches, nil
+}
+
+// suppressOverlaps Keeps the best of overlapping matches, the matches must be sorted
+func suppressOverlaps(matches []ImageMatch) []ImageMatch {
+	kept := matches[:0]
+	for _, m := range matches {
+		overlapping := false
+		for _, k := range kept {
+			inter := m.Rect.Intersect(k.Rect)
+			if !inter.Empty() && inter.Dx()*inter.Dy()*2 > m.Rect.Dx()*m.Rect.Dy() {
+				overlapping = true
+				break
+			}
+		}
+		if !overlapping {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+type grayImage struct {
+	w, h int
+	pix  []float64
+}
+
+func newGrayImage(img image.Image, bounds image.Rectangle) *grayImage {
+	g := &grayImage{w: bounds.Dx(), h: bounds.Dy(), pix: make([]float64, bounds.Dx()*bounds.Dy())}
+	for y := 0; y < g.h; y++ {
+		for x := 0; x < g.w; x++ {
+			g.pix[y*g.w+x] = float64(color.GrayModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.Gray).Y)
+		}
+	}
+	return g
+}
+
+// resize Averages the source pixels covered by each pixel
+func (g *grayImage) resize(w, h int) *grayImage {
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	dst := &grayImage{w: w, h: h, pix: make([]float64, w*h)}
+	for y := 0; y < h; y++ {
+		y0, y1 := y*g.h/h, (y+1)*g.h/h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0, x1 := x*g.w/w, (x+1)*g.w/w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			sum := 0.0
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					sum += g.pix[sy*g.w+sx]
+				}
+			}
+			dst.pix[y*w+x] = sum / float64((y1-y0)*(x1-x0))
+		}
+	}
+	return dst
+}
+
+// ncc Computes the zero-mean normalized cross-correlation,
+// using integral images for the sums over the image
+type ncc struct {
+	img, tpl *grayImage
+	tplZero  []float64
+	tplVar   float64
+	sum, sq  []float64
+}
+
+func newNCC(img, tpl *grayImage) (*ncc, error) {
+	n := &ncc{img: img, tpl: tpl, tplZero: make([]float64, len(tpl.pix))}
+	mean := 0.0
+	for _, v := range tpl.pix {
+		mean += v
+	}
+	mean /= float64(len(tpl.pix))
+	for i, v := range tpl.pix {
+		n.tplZero[i] = v - mean
+		n.tplVar += n.tplZero[i] * n.tplZero[i]
+	}
+	if n.tplVar < 1e-6 {
+		return nil, errFlatTemplate
+	}
+
+	stride := img.w + 1
+	n.sum = make([]float64, stride*(img.h+1))
+	n.sq = make([]float64, stride*(img.h+1))
+	for y := 0; y < img.h; y++ {
+		rowSum, rowSq := 0.0, 0.0
+		for x := 0; x < img.w; x++ {
+			v := img.pix[y*img.w+x]
+			rowSum += v
+			rowSq += v * v
+			n.sum[(y+1)*stride+x+1] = n.sum[y*stride+x+1] + rowSum
+			n.sq[(y+1)*stride+x+1] = n.sq[y*stride+x+1] + rowSq
+		}
+	}
+	return n, nil
+}
+
+func (n *ncc) at(x, y int) float64 {
+	stride, w, h := n.img.w+1, n.tpl.w, n.tpl.h
+	area := func(s []float64) float64 {
+		return s[(y+h)*stride+x+w] - s[y*stride+x+w] - s[(y+h)*stride+x] + s[y*stride+x]
+	}
+	count := float64(w * h)
+	sum := area(n.sum)
+	imgVar := area(n.sq) - sum*sum/count
+	if imgVar < 1e-6 {
+		return 0
+	}
+	cross := 0.0
+	for ty := 0; ty < h; ty++ {
+		row := n.img.pix[(y+ty)*n.img.w+x : (y+ty)*n.img.w+x+w]
+		tplRow := n.tplZero[ty*w : (ty+1)*w]
+		for tx := range tplRow {
+			cross += tplRow[tx] * row[tx]
+		}
+	}
+	return cross / math.Sqrt(n.tplVar*imgVar)
+}
+
+// peaks Returns the local maxima within the positions (x0, y0)-(x1, y1) at or above the threshold, best first
+func (n *ncc) peaks(x0, y0, x1, y1 int, threshold float64, radius int) (matches []ImageMatch) {
+	if x0 < 0 {
+		x0 = 0
+	}
+	if y0 < 0 {
+		y0 = 0
+	}
+	if max := n.img.w - n.tpl.w; x1 > max {
+		x1 = max
+	}
+	if max := n.img.h - n.tpl.h; y1 > max {
+		y1 = max
+	}
+	if x1 < x0 || y1 < y0 {
+		return nil
+	}
+	w := x1 - x0 + 1
+	scores := make([]float64, w*(y1-y0+1))
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			scores[(y-y0)*w+x-x0] = n.at(x, y)
+		}
+	}
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			score := scores[(y-y0)*w+x-x0]
+			if score < threshold || !isLocalMax(scores, w, x-x0, y-y0, radius) {
+				continue
+			}
+			matches = append(matches, ImageMatch{Rect: image.Rect(x, y, x+n.tpl.w, y+n.tpl.h), Confidence: score})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Confidence > matches[j].Confidence })
+	return matches
+}
+
+func isLocalMax(scores []float64, w, x, y, radius int) bool {
+	h := len(scores) / w
+	score := scores[y*w+x]
+	for ny := y - radius; ny <= y+radius; ny++ {
+		for nx := x - radius; nx <= x+radius; nx++ {
+			if nx < 0 || ny < 0 || nx >= w || ny >= h || (nx == x && ny == y) {
+				continue
+			}
+			other := scores[ny*w+nx]
+			// ties are resolved towards the top left
+			if other > score || (other == score && (ny < y || (ny == y && nx < x))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// FindImage Searches a screenshot of the device for the template.
+// The matches carry both pixel and point coordinates.
+func FindImage(driver WebDriver, template image.Image, opts ...TemplateOption) (matches []ImageMatch, err error) {
+	var screenshot DecodedScreenshot
+	if screenshot, err = driver.ScreenshotImage(); err != nil {
+		return nil, err
+	}
+	if matches, err = MatchTemplate(screenshot.Image, template, opts...); err != nil {
+		return nil, err
+	}
+	for i := range matches {
+		minX, minY := screenshot.PixelToPoint(matches[i].Rect.Min.X, matches[i].Rect.Min.Y)
+		maxX, maxY := screenshot.PixelToPoint(matches[i].Rect.Max.X, matches[i].Rect.Max.Y)
+		matches[i].PointRect = Rect{
+			Point: Point{X: int(math.Round(minX)), Y: int(math.Round(minY))},
+			Size:  Size{Width: int(math.Round(maxX - minX)), Height: int(math.Round(maxY - minY))},
+		}
+	}
+	return matches, nil
+}
+
+// WaitForImage Waits until the template appears on the screen and returns the best match
+func WaitForImage(driver WebDriver, template image.Image, opts ...TemplateOption) (match ImageMatch, err error) {
+	tm := newTemplateMatcher(opts...)
+	err = driver.WaitWithTimeoutAndInterval(func(wd WebDriver) (bool, error) {
+		matches, e := FindImage(wd, template, opts...)
+		if e != nil {
+			return false, e
+		}
+		if len(matches) == 0 {
+			return false, nil
+		}
+		match = matches[0]
+		return true, nil
+	}, tm.timeout, DefaultWaitInterval)
+	if err != nil {
+		return ImageMatch{}, fmt.Errorf("wait for image: %w", err)
+	}
+	return match, nil
+}
+
+// TapImage Taps the center of the best match of the template
+func TapImage(driver WebDriver, template image.Image, opts ...TemplateOption) (err error) {
+	var matches []ImageMatch
+	if matches, err = FindImage(driver, template, opts...); err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("%w: image", errNoSuchElement)
+	}
+	rect := matches[0].PointRect
+	return driver.TapFloat(float64(rect.X)+float64(rect.Width)/2, float64(rect.Y)+float64(rect.Height)/2)
+}
@@ -0,0 +1,108 @@
+package gwda
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"testing"
+)
+
+// newBlockImage Fills the image with random gray blocks of 4x4 pixels
+func newBlockImage(w, h int, seed int64) *image.Gray {
+	rnd := rand.New(rand.NewSource(seed))
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for by := 0; by < h; by += 4 {
+		for bx := 0; bx < w; bx += 4 {
+			draw.Draw(img, image.Rect(bx, by, bx+4, by+4), image.NewUniform(color.Gray{Y: uint8(rnd.Intn(256))}), image.Point{}, draw.Src)
+		}
+	}
+	return img
+}
+
+func TestMatchTemplate(t *testing.T) {
+	screen := newBlockImage(320, 240, 1)
+	template := screen.SubImage(image.Rect(123, 77, 171, 117))
+
+	matches, err := MatchTemplate(screen, template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Rect != image.Rect(123, 77, 171, 117) || matches[0].Confidence < 0.99 {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+
+	// a template captured on a screen with twice the scale
+	scaled := image.NewGray(image.Rect(0, 0, 128, 128))
+	for y := 0; y < 128; y++ {
+		for x := 0; x < 128; x++ {
+			scaled.Set(x, y, screen.At(40+x/2, 100+y/2))
+		}
+	}
+	if matches, err = MatchTemplate(screen, scaled, WithTemplateScales(1, 0.5)); err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Rect != image.Rect(40, 100, 104, 164) || matches[0].Scale != 0.5 {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+
+	if matches, err = MatchTemplate(screen, template, WithTemplateRegion(image.Rect(0, 0, 100, 100))); err != nil || len(matches) != 0 {
+		t.Fatalf("expected no match outside the region: %v %+v", err, matches)
+	}
+
+	if _, err = MatchTemplate(screen, image.NewGray(image.Rect(0, 0, 20, 20))); err != errFlatTemplate {
+		t.Fatalf("expected %v, got %v", errFlatTemplate, err)
+	}
+
+	// a checkerboard of 1 pixel is flat once downscaled, it is searched at full resolution
+	checker := image.NewGray(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			if (x+y)%2 == 0 {
+				checker.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	draw.Draw(screen, image.Rect(201, 103, 233, 135), checker, image.Point{}, draw.Src)
+	if matches, err = MatchTemplate(screen, checker); err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) == 0 || matches[0].Rect != image.Rect(201, 103, 233, 135) || matches[0].Confidence < 0.99 {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+}
+
+type fakeImageDriver struct {
+	WebDriver
+	screen     image.Image
+	tapX, tapY float64
+}
+
+func (wd *fakeImageDriver) ScreenshotImage() (DecodedScreenshot, error) {
+	return DecodedScreenshot{
+		Image:     wd.screen,
+		Format:    "png",
+		PixelSize: Size{Width: 320, Height: 240},
+		PointSize: Size{Width: 160, Height: 120},
+		Scale:     2,
+	}, nil
+}
+
+func (wd *fakeImageDriver) TapFloat(x, y float64) error {
+	wd.tapX, wd.tapY = x, y
+	return nil
+}
+
+func TestTapImage(t *testing.T) {
+	screen := newBlockImage(320, 240, 2)
+	wd := &fakeImageDriver{screen: screen}
+	if err := TapImage(wd, screen.SubImage(image.Rect(200, 40, 240, 80))); err != nil {
+		t.Fatal(err)
+	}
+	if wd.tapX != 110 || wd.tapY != 30 {
+		t.Fatalf("unexpected tap: (%v, %v)", wd.tapX, wd.tapY)
+	}
+	if err := TapImage(wd, newBlockImage(40, 40, 3)); err == nil {
+		t.Fatal("expected no match")
+	}
+}
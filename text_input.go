@@ -0,0 +1,183 @@
+package gwda
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// PasteMode Decides when `WebElement.TypeText` pastes the text instead of typing it
+type PasteMode int
+
+const (
+	// PasteModeAuto pastes long texts and texts with complex characters such as emoji sequences
+	PasteModeAuto PasteMode = iota
+	PasteModeNever
+	PasteModeAlways
+)
+
+// TypeTextOption Configure the behavior of `WebElement.TypeText`
+type TypeTextOption func(tt *typeText)
+
+// WithTypeTextFrequency Frequency of typing (letters per sec).
+//  Defaults to `60`
+func WithTypeTextFrequency(n int) TypeTextOption {
+	return func(tt *typeText) {
+		tt.frequency = n
+	}
+}
+
+// WithTypeTextPaste Defaults to `PasteModeAuto`
+func WithTypeTextPaste(mode PasteMode) TypeTextOption {
+	return func(tt *typeText) {
+		tt.pasteMode = mode
+	}
+}
+
+// WithTypeTextPasteThreshold The number of characters above which `PasteModeAuto` pastes.
+//  Defaults to `100`
+func WithTypeTextPasteThreshold(n int) TypeTextOption {
+	return func(tt *typeText) {
+		tt.pasteThreshold = n
+	}
+}
+
+// WithTypeTextPasteMenuLabel The label of the edit menu item that pastes, for localized devices.
+//  Defaults to `Paste`
+func WithTypeTextPasteMenuLabel(label string) TypeTextOption {
+	return func(tt *typeText) {
+		tt.pasteLabel = label
+	}
+}
+
+// WithTypeTextVerify Reads the element back with `WebElement.Text` after the input.
+// On mismatch the element is cleared and the text entered again, pasting it if allowed.
+//  retries: The number of additional attempts
+func WithTypeTextVerify(retries int) TypeTextOption {
+	return func(tt *typeText) {
+		tt.verify = true
+		tt.retries = retries
+	}
+}
+
+type typeText struct {
+	wd   WebDriver
+	elem WebElement
+
+	frequency      int
+	pasteMode      PasteMode
+	pasteThreshold int
+	pasteLabel     string
+	verify         bool
+	retries        int
+}
+
+func newTypeText(wd WebDriver, elem WebElement, opts ...TypeTextOption) *typeText {
+	tt := &typeText{
+		wd:             wd,
+		elem:           elem,
+		frequency:      60,
+		pasteMode:      PasteModeAuto,
+		pasteThreshold: 100,
+		pasteLabel:     "Paste",
+	}
+	for _, opt := range opts {
+		opt(tt)
+	}
+	return tt
+}
+
+// shouldPaste Whether the text is long or contains characters made of several code points,
+// which the on-screen keyboard often fails to type.
+func (tt *typeText) shouldPaste(text string) bool {
+	switch tt.pasteMode {
+	case PasteModeNever:
+		return false
+	case PasteModeAlways:
+		return true
+	}
+	graphemes := splitGraphemes(text)
+	if tt.pasteThreshold > 0 && len(graphemes) > tt.pasteThreshold {
+		return true
+	}
+	for i := range graphemes {
+		if utf8.RuneCountInString(graphemes[i]) > 1 && graphemes[i] != "\r\n" {
+			return true
+		}
+	}
+	return false
+}
+
+func (tt *typeText) run(text string) (err error) {
+	paste := tt.shouldPaste(text)
+	attempts := 1
+	if tt.verify && tt.retries > 0 {
+		attempts += tt.retries
+	}
+	// the text is appended to the current value, which is empty once cleared
+	var before string
+	if tt.verify {
+		if before, err = tt.elem.Text(); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < attempts; i++ {
+		if i != 0 {
+			if err = tt.elem.Clear(); err != nil {
+				return err
+			}
+			before = ""
+			// typing failed before, so paste if allowed
+			paste = paste || tt.pasteMode != PasteModeNever
+		}
+		if paste {
+			err = tt.paste(text)
+		} else {
+			err = tt.elem.SendKeys(text, tt.frequency)
+		}
+		if err != nil {
+			return err
+		}
+		if !tt.verify {
+			return nil
+		}
+		var actual string
+		if actual, err = tt.elem.Text(); err != nil {
+			return err
+		}
+		if actual == before+text {
+			return nil
+		}
+		err = fmt.Errorf("text mismatch: expected '%s', got '%s'", before+text, actual)
+	}
+	return err
+}
+
+func (tt *typeText) paste(text string) (err error) {
+	if err = tt.wd.SetPasteboard(PasteboardTypePlaintext, text); err != nil {
+		return err
+	}
+	if err = tt.elem.Click(); err != nil {
+		return err
+	}
+	if err = tt.elem.TouchAndHold(); err != nil {
+		return err
+	}
+	by := BySelector{Predicate: fmt.Sprintf(`type == "XCUIElementTypeMenuItem" AND (name == %s OR label == %s)`, predicateLiteral(tt.pasteLabel), predicateLiteral(tt.pasteLabel))}
+	var menuItem WebElement
+	err = tt.wd.WaitWithTimeoutAndInterval(func(wd WebDriver) (bool, error) {
+		var e error
+		if menuItem, e = wd.FindElement(by); e != nil {
+			if errors.Is(e, errNoSuchElement) {
+				return false, nil
+			}
+			return false, e
+		}
+		return true, nil
+	}, 3*time.Second, DefaultWaitInterval)
+	if err != nil {
+		return fmt.Errorf("paste menu item '%s': %w", tt.pasteLabel, err)
+	}
+	return menuItem.Click()
+}
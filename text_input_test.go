@@ -0,0 +1,119 @@
+package gwda
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTextField drops everything but ASCII when typed, like a keyboard without an emoji layout.
+type fakeTextField struct {
+	WebElement
+	text   string
+	typed  int
+	wd     *fakePasteDriver
+	cleans int
+}
+
+func (f *fakeTextField) SendKeys(text string, _ ...int) error {
+	f.typed++
+	f.text += strings.Map(func(r rune) rune {
+		if r > 0x7F {
+			return -1
+		}
+		return r
+	}, text)
+	return nil
+}
+
+func (f *fakeTextField) Text() (string, error) { return f.text, nil }
+
+func (f *fakeTextField) Clear() error { f.cleans++; f.text = ""; return nil }
+
+func (f *fakeTextField) Click() error { return nil }
+
+func (f *fakeTextField) TouchAndHold(_ ...float64) error { return nil }
+
+type fakeMenuItem struct {
+	WebElement
+	field *fakeTextField
+}
+
+func (m fakeMenuItem) Click() error {
+	m.field.text += m.field.wd.pasteboard
+	return nil
+}
+
+type fakePasteDriver struct {
+	WebDriver
+	field      *fakeTextField
+	pasteboard string
+	found      []BySelector
+}
+
+func (wd *fakePasteDriver) SetPasteboard(_ PasteboardType, content string) error {
+	wd.pasteboard = content
+	return nil
+}
+
+func (wd *fakePasteDriver) FindElement(by BySelector) (WebElement, error) {
+	wd.found = append(wd.found, by)
+	return fakeMenuItem{field: wd.field}, nil
+}
+
+func (wd *fakePasteDriver) WaitWithTimeoutAndInterval(condition Condition, _, _ time.Duration) error {
+	_, err := condition(wd)
+	return err
+}
+
+func newFakeTextField() (*fakePasteDriver, *fakeTextField) {
+	wd := &fakePasteDriver{}
+	wd.field = &fakeTextField{wd: wd}
+	return wd, wd.field
+}
+
+func Test_typeText(t *testing.T) {
+	wd, field := newFakeTextField()
+	if err := newTypeText(wd, field).run("hello"); err != nil || field.text != "hello" || field.typed != 1 {
+		t.Fatalf("expected to type, got %q (%v)", field.text, err)
+	}
+
+	wd, field = newFakeTextField()
+	text := "hi \U0001F44B\U0001F3FD"
+	if err := newTypeText(wd, field).run(text); err != nil || field.text != text || field.typed != 0 {
+		t.Fatalf("expected to paste, got %q (%v)", field.text, err)
+	}
+
+	wd, field = newFakeTextField()
+	err := newTypeText(wd, field, WithTypeTextPaste(PasteModeNever), WithTypeTextVerify(2)).run("café")
+	if err == nil || field.typed != 3 {
+		t.Fatalf("expected a mismatch after 3 attempts, got %d attempts (%v)", field.typed, err)
+	}
+
+	wd, field = newFakeTextField()
+	err = newTypeText(wd, field, WithTypeTextPasteThreshold(0), WithTypeTextVerify(1)).run("café")
+	if err != nil || field.text != "café" || field.typed != 1 || field.cleans != 1 {
+		t.Fatalf("expected to paste after typing failed, got %q (%v)", field.text, err)
+	}
+
+	// the text already in the field does not count as typed
+	wd, field = newFakeTextField()
+	field.text = "café"
+	err = newTypeText(wd, field, WithTypeTextPaste(PasteModeNever), WithTypeTextVerify(0)).run("é")
+	if err == nil {
+		t.Fatalf("expected a mismatch, got %q", field.text)
+	}
+	wd, field = newFakeTextField()
+	field.text = "hello "
+	err = newTypeText(wd, field, WithTypeTextVerify(0)).run("world")
+	if err != nil || field.text != "hello world" {
+		t.Fatalf("expected to append, got %q (%v)", field.text, err)
+	}
+
+	wd, field = newFakeTextField()
+	err = newTypeText(wd, field, WithTypeTextPaste(PasteModeAlways), WithTypeTextPasteMenuLabel("Coller l'\"x\"")).run("abc")
+	expected := `type == "XCUIElementTypeMenuItem" AND (name == "Coller l'\"x\"" OR label == "Coller l'\"x\"")`
+	if err != nil || len(wd.found) != 1 || wd.found[0].Predicate != expected {
+		t.Fatalf("unexpected paste menu item lookup %+v (%v)", wd.found, err)
+	}
+}
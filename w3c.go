@@ -0,0 +1,307 @@
+package gwda
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// W3CSequence The typed form of W3CActions, see https://www.w3.org/TR/webdriver/#actions
+type W3CSequence []W3CInputSource
+
+// W3CInputSource An input source and the actions it performs, one action per tick.
+type W3CInputSource struct {
+	// Type one of `pointer`, `key` and `none`
+	Type       string                `json:"type"`
+	ID         string                `json:"id"`
+	Parameters *W3CPointerParameters `json:"parameters,omitempty"`
+	Actions    []W3CAction           `json:"actions"`
+}
+
+type W3CPointerParameters struct {
+	// PointerType one of `mouse`, `pen` and `touch`
+	PointerType string `json:"pointerType"`
+}
+
+// W3CAction A single action of an input source.
+//  Duration: in milliseconds, unlike the seconds taken by `FingerAction.Pause` and `FingerMove.WithDuration`
+type W3CAction struct {
+	Type     string     `json:"type"`
+	Duration *float64   `json:"duration,omitempty"`
+	X        *float64   `json:"x,omitempty"`
+	Y        *float64   `json:"y,omitempty"`
+	Origin   *W3COrigin `json:"origin,omitempty"`
+	Button   *int       `json:"button,omitempty"`
+	Value    string     `json:"value,omitempty"`
+}
+
+// W3COrigin The origin of a pointer move: `viewport`, `pointer` or an element.
+// An element is sent as the W3C element reference `{"element-6066-11e4-a52e-4f735466cecf": "UID"}`,
+// or as its bare UID, which WDA also accepts, if it was parsed so.
+type W3COrigin struct {
+	// Name `viewport` or `pointer`, empty for an element
+	Name string
+	// ElementID The UID of the element
+	ElementID string
+
+	bare bool
+}
+
+// NewW3CElementOrigin The origin of the moves relative to the element
+func NewW3CElementOrigin(element WebElement) *W3COrigin {
+	return &W3COrigin{ElementID: element.UID()}
+}
+
+func (o W3COrigin) MarshalJSON() ([]byte, error) {
+	switch {
+	case o.ElementID == "":
+		return json.Marshal(o.Name)
+	case o.bare:
+		return json.Marshal(o.ElementID)
+	}
+	return json.Marshal(map[string]string{webElementIdentifier: o.ElementID})
+}
+
+func (o *W3COrigin) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s == "viewport" || s == "pointer" {
+			*o = W3COrigin{Name: s}
+		} else {
+			*o = W3COrigin{ElementID: s, bare: true}
+		}
+		return nil
+	}
+	var reference map[string]string
+	if err := json.Unmarshal(data, &reference); err != nil {
+		return fmt.Errorf("origin: expected `viewport`, `pointer` or an element: %w", err)
+	}
+	if *o = (W3COrigin{ElementID: elementIDFromValue(reference)}); o.ElementID == "" {
+		return fmt.Errorf("origin: invalid element reference %s", data)
+	}
+	return nil
+}
+
+func (o W3COrigin) String() string {
+	if o.ElementID != "" {
+		return "element " + o.ElementID
+	}
+	return o.Name
+}
+
+// ParseW3CSequence Parses and validates either a list of input sources
+// or a request body in form of `{"actions": [...]}`.
+func ParseW3CSequence(data []byte) (seq W3CSequence, err error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("{")) {
+		var body struct {
+			Actions W3CSequence `json:"actions"`
+		}
+		if err = json.Unmarshal(data, &body); err != nil {
+			return nil, err
+		}
+		seq = body.Actions
+	} else if err = json.Unmarshal(data, &seq); err != nil {
+		return nil, err
+	}
+	if err = seq.Validate(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ParseW3CActions works like ParseW3CSequence, but returns W3CActions.
+func ParseW3CActions(data []byte) (*W3CActions, error) {
+	seq, err := ParseW3CSequence(data)
+	if err != nil {
+		return nil, err
+	}
+	return seq.Actions()
+}
+
+// Sequence Converts the actions into the typed form
+func (act W3CActions) Sequence() (seq W3CSequence, err error) {
+	var bsJSON []byte
+	if bsJSON, err = json.Marshal(act); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(bsJSON, &seq); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Validate Reports the first invalid action that would otherwise only be rejected by the device
+func (act W3CActions) Validate() error {
+	seq, err := act.Sequence()
+	if err != nil {
+		return err
+	}
+	return seq.Validate()
+}
+
+// String Pretty prints the actions for debugging
+func (act W3CActions) String() string {
+	seq, err := act.Sequence()
+	if err != nil {
+		return "invalid actions: " + err.Error()
+	}
+	return seq.String()
+}
+
+// Actions Converts the sequence back into W3CActions
+func (seq W3CSequence) Actions() (act *W3CActions, err error) {
+	var bsJSON []byte
+	if bsJSON, err = json.Marshal(seq); err != nil {
+		return nil, err
+	}
+	act = NewW3CActions(len(seq))
+	if err = json.Unmarshal(bsJSON, act); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Validate Reports the first structural error, e.g. an unknown action type or a missing field.
+// The sources may have different numbers of ticks, the missing ones being pauses.
+func (seq W3CSequence) Validate() error {
+	if len(seq) == 0 {
+		return errors.New("actions must not be empty")
+	}
+	ids := make(map[string]bool, len(seq))
+	for i := range seq {
+		source := seq[i]
+		if source.ID == "" {
+			return fmt.Errorf("actions[%d]: 'id' must not be empty", i)
+		}
+		if ids[source.ID] {
+			return fmt.Errorf("actions[%d]: duplicate id '%s'", i, source.ID)
+		}
+		ids[source.ID] = true
+		if err := source.validate(); err != nil {
+			return fmt.Errorf("actions[%d] (%s): %w", i, source.ID, err)
+		}
+	}
+	return nil
+}
+
+func (source W3CInputSource) validate() error {
+	var allowed []string
+	switch source.Type {
+	case "pointer":
+		allowed = []string{"pause", "pointerMove", "pointerDown", "pointerUp", "pointerCancel"}
+		if source.Parameters != nil {
+			switch source.Parameters.PointerType {
+			case "mouse", "pen", "touch":
+			default:
+				return fmt.Errorf("unknown pointerType '%s'", source.Parameters.PointerType)
+			}
+		}
+	case "key":
+		allowed = []string{"pause", "keyDown", "keyUp"}
+	case "none":
+		allowed = []string{"pause"}
+	default:
+		return fmt.Errorf("unknown type '%s'", source.Type)
+	}
+
+	pointerDown := false
+	keysDown := make(map[string]bool)
+	for i, action := range source.Actions {
+		if !containsString(allowed, action.Type) {
+			return fmt.Errorf("action %d: '%s' is not supported by a '%s' source", i, action.Type, source.Type)
+		}
+		if action.Duration != nil && *action.Duration < 0 {
+			return fmt.Errorf("action %d: '%s' with negative duration %v", i, action.Type, *action.Duration)
+		}
+		switch action.Type {
+		case "pointerMove":
+			if action.Origin != nil && action.Origin.ElementID == "" && action.Origin.Name != "" && action.Origin.Name != "viewport" && action.Origin.Name != "pointer" {
+				return fmt.Errorf("action %d: unknown origin '%s'", i, action.Origin.Name)
+			}
+			isElement := action.Origin != nil && action.Origin.ElementID != ""
+			if (action.X == nil || action.Y == nil) && !isElement {
+				return fmt.Errorf("action %d: 'pointerMove' requires both 'x' and 'y'", i)
+			}
+		case "pointerDown":
+			if pointerDown {
+				return fmt.Errorf("action %d: 'pointerDown' while the pointer is already down", i)
+			}
+			pointerDown = true
+		case "pointerUp", "pointerCancel":
+			if !pointerDown {
+				return fmt.Errorf("action %d: '%s' while the pointer is not down", i, action.Type)
+			}
+			pointerDown = false
+		case "keyDown":
+			if len(splitGraphemes(action.Value)) != 1 {
+				return fmt.Errorf("action %d: 'keyDown' requires a single key as 'value', got '%s'", i, action.Value)
+			}
+			keysDown[action.Value] = true
+		case "keyUp":
+			if !keysDown[action.Value] {
+				return fmt.Errorf("action %d: 'keyUp' of '%s' which is not down", i, action.Value)
+			}
+			delete(keysDown, action.Value)
+		}
+	}
+	// a pointer left down is allowed, e.g. to continue the gesture with the next actions
+	return nil
+}
+
+func containsString(ss []string, s string) bool {
+	for i := range ss {
+		if ss[i] == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (seq W3CSequence) String() string {
+	var sb strings.Builder
+	for i, source := range seq {
+		if i != 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(source.ID + " (" + source.Type)
+		if source.Parameters != nil {
+			sb.WriteString(", " + source.Parameters.PointerType)
+		}
+		sb.WriteString(")")
+		for tick, action := range source.Actions {
+			sb.WriteString(fmt.Sprintf("\n  %3d  %s", tick, action))
+		}
+	}
+	return sb.String()
+}
+
+func (action W3CAction) String() string {
+	ss := []string{action.Type}
+	if action.X != nil || action.Y != nil {
+		ss = append(ss, fmt.Sprintf("(%s, %s)", formatOptionalFloat(action.X), formatOptionalFloat(action.Y)))
+	}
+	if action.Origin != nil {
+		ss = append(ss, "origin="+action.Origin.String())
+	}
+	if action.Value != "" {
+		ss = append(ss, "value="+strconv.QuoteToASCII(action.Value))
+	}
+	if action.Button != nil {
+		ss = append(ss, "button="+strconv.Itoa(*action.Button))
+	}
+	if action.Duration != nil {
+		ss = append(ss, formatOptionalFloat(action.Duration)+"ms")
+	}
+	return strings.Join(ss, " ")
+}
+
+func formatOptionalFloat(f *float64) string {
+	if f == nil {
+		return "?"
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
@@ -0,0 +1,130 @@
+package gwda
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestW3CActions_Validate(t *testing.T) {
+	valid := []*W3CActions{
+		NewW3CActions().Tap(10, 20),
+		NewW3CActions().Swipe(10, 20, 30, 40),
+		NewW3CActions().PinchIn(100, 100, 80, 0.3),
+		NewW3CActions().SendKeys("abc"),
+		// a pointer left down
+		NewW3CActions().FingerAction(NewFingerAction().Move(NewFingerMove().WithXY(1, 1)).Down()),
+		// the sources of different lengths pause after their last action
+		NewW3CActions().Tap(1, 1).Swipe(1, 1, 2, 2).SendKeys("abc"),
+		NewW3CActions().FingerAction(NewFingerAction().
+			Move(NewFingerMove().WithOrigin(remoteWE{id: "E1"})).Down().Up()),
+	}
+	for i, act := range valid {
+		if err := act.Validate(); err != nil {
+			t.Errorf("%d: %v\n%s", i, err, act)
+		}
+	}
+
+	invalid := map[string]*W3CActions{
+		"requires both 'x' and 'y'": NewW3CActions().FingerAction(NewFingerAction().
+			Move(NewFingerMove()).Down().Up()),
+		"negative duration": NewW3CActions().FingerAction(NewFingerAction().
+			Move(NewFingerMove().WithXY(1, 1).WithDuration(-1)).Down().Up()),
+		"already down": NewW3CActions().FingerAction(NewFingerAction().
+			Move(NewFingerMove().WithXY(1, 1)).Down().Down().Up()),
+	}
+	for want, act := range invalid {
+		if err := act.Validate(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestFingerMove_WithDuration(t *testing.T) {
+	seq, err := NewW3CActions().FingerAction(NewFingerAction().
+		Move(NewFingerMove().WithXY(1, 1)).Down().Pause(0.25).
+		Move(NewFingerMove().WithXY(2, 2).WithDuration(0.25)).Up()).Sequence()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pause, move := seq[0].Actions[2], seq[0].Actions[3]
+	if *pause.Duration != 250 || *move.Duration != 250 {
+		t.Fatalf("expected both durations in milliseconds, got %v and %v", *pause.Duration, *move.Duration)
+	}
+}
+
+func TestParseW3CSequence(t *testing.T) {
+	act := NewW3CActions().DoubleTap(10, 20).FingerAction(NewFingerAction().
+		Move(NewFingerMove().WithXY(5, 5)).Down().Pause(0.1).Up().Pause(0.04).Down().Pause(0.1).Up())
+	bsJSON, err := json.Marshal(map[string]interface{}{"actions": act})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seq, err := ParseW3CSequence(bsJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := act.Sequence()
+	if !reflect.DeepEqual(seq, expected) {
+		t.Fatalf("round trip mismatch:\n%s\n%s", seq, expected)
+	}
+
+	roundTrip, err := seq.Actions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := roundTrip.Sequence(); !reflect.DeepEqual(again, seq) {
+		t.Fatalf("round trip mismatch:\n%s\n%s", again, seq)
+	}
+
+	if _, err = ParseW3CSequence([]byte(`[{"type":"pointer","id":"f1","actions":[{"type":"pause","duration":-5}]}]`)); err == nil {
+		t.Fatal("expected an invalid sequence")
+	}
+}
+
+func TestW3COrigin(t *testing.T) {
+	data := []byte(`[{"type":"pointer","id":"f1","parameters":{"pointerType":"touch"},"actions":[
+		{"type":"pointerMove","origin":{"element-6066-11e4-a52e-4f735466cecf":"E1"}},
+		{"type":"pointerMove","origin":"E2"},
+		{"type":"pointerMove","origin":"pointer","x":1,"y":1}]}]`)
+	seq, err := ParseW3CSequence(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := seq[0].Actions
+	if actions[0].Origin.ElementID != "E1" || actions[1].Origin.ElementID != "E2" || actions[2].Origin.Name != "pointer" {
+		t.Fatalf("unexpected origins: %s", seq)
+	}
+	bsJSON, err := json.Marshal(seq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"origin":{"element-6066-11e4-a52e-4f735466cecf":"E1"}`, `"origin":"E2"`, `"origin":"pointer"`} {
+		if !strings.Contains(string(bsJSON), want) {
+			t.Errorf("expected %s in %s", want, bsJSON)
+		}
+	}
+
+	act := NewW3CActions().FingerAction(NewFingerAction().Move(NewFingerMove().WithXY(1, 1)).Down().Up())
+	if seq, err = act.Sequence(); err != nil {
+		t.Fatal(err)
+	}
+	seq[0].Actions[0].Origin = NewW3CElementOrigin(remoteWE{id: "E3"})
+	if bsJSON, err = json.Marshal(seq); err != nil || !strings.Contains(string(bsJSON), `"origin":{"element-6066-11e4-a52e-4f735466cecf":"E3"}`) {
+		t.Fatalf("unexpected element origin: %s %v", bsJSON, err)
+	}
+
+	if _, err = ParseW3CSequence([]byte(`[{"type":"pointer","id":"f1","actions":[{"type":"pointerMove","origin":{"id":"E1"}}]}]`)); err == nil {
+		t.Fatal("expected an invalid element reference")
+	}
+}
+
+func TestW3CActions_String(t *testing.T) {
+	s := NewW3CActions().Tap(10, 20).String()
+	for _, want := range []string{"finger1 (pointer, touch)", "pointerMove (10, 20)", "pause 100ms", "pointerUp"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("expected %q in:\n%s", want, s)
+		}
+	}
+}